@@ -1,116 +1,409 @@
 package main
 
 import (
+	"bytes"
 	"context"
-	"encoding/csv"
 	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
 	"path/filepath"
+	"strconv"
+	"strings"
+
+	"matrix/pkg/matrix"
 )
 
 var (
-	errInvalidFileFormatCSV = errors.New("invalid file format, only CSV allowed")
-	errNotSquareMatrix      = errors.New("matrix should be square")
-	errEmptyRecord          = errors.New("matrix shouldn't be empty")
+	errInvalidFileFormat = errors.New("invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed")
+	errMissingFile       = errors.New("no such file in the multipart form")
+	errMatrixTooLarge    = errors.New("matrix is too large")
+	errCellTooLong       = errors.New("matrix element is too long")
+	errBodyTooLarge      = errors.New("request body is too large")
+	errSingleCsvBody     = errors.New("binary operations need two matrices, send them as multipart form or JSON")
+	errInvalidIndices    = errors.New("invalid indices, true or false allowed")
+	errInvalidLabels     = errors.New("invalid labels, true or false allowed")
 )
 
+// tableContentTypes are the content types of the human-readable tables
+var tableContentTypes = map[format]string{
+	tableFormat:    "text/plain; charset=utf-8",
+	markdownFormat: "text/markdown; charset=utf-8",
+	latexFormat:    "application/x-latex",
+	htmlFormat:     "text/html; charset=utf-8",
+}
+
+const pngContentType = "image/png"
+
 type Handler struct {
 	mux *http.ServeMux
 }
 
-func NewHandler() *http.ServeMux {
+func NewHandler(l limits) http.Handler {
 	handler := Handler{}
 	mux := http.NewServeMux()
-	mux.Handle("/echo", getRecordsMiddleware(handler.Echo))
-	mux.Handle("/invert", getRecordsMiddleware(handler.Invert))
-	mux.Handle("/multiply", getRecordsMiddleware(handler.Multiply))
-	mux.Handle("/flatten", getRecordsMiddleware(handler.Flatten))
-	mux.Handle("/sum", getRecordsMiddleware(handler.Sum))
-	return mux
+	for _, op := range operations {
+		mux.Handle("/"+op.Name(), serveOperation(op))
+	}
+	transpose, _ := lookupOperation("transpose")
+	// deprecated: /invert performs a transpose, kept for backward compatibility of existing clients
+	mux.Handle("/invert", deprecatedMiddleware("/transpose", serveOperation(transpose).ServeHTTP))
+	mux.HandleFunc("/operations", handler.Operations)
+	mux.HandleFunc("/", handler.UI)
+	return limitsMiddleware(formatMiddleware(mux), l)
 }
 
-func (Handler) Echo(w http.ResponseWriter, r *http.Request) {
-	records := getRecordsFromCtx(r.Context())
-	fmt.Fprint(w, matrixToString(records))
+func getLimitsFromCtx(ctx context.Context) limits {
+	return ctx.Value(limitsKey).(limits)
 }
 
-func (Handler) Invert(w http.ResponseWriter, r *http.Request) {
-	records := getRecordsFromCtx(r.Context())
-	records = invertMatrix(records)
-	fmt.Fprint(w, matrixToString(records))
+// numericMiddleware resolves the numeric type requested with the "type" query parameter
+func numericMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		typ, err := getNumericType(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		ctxWithType := context.WithValue(r.Context(), numericTypeKey, typ)
+		handler.ServeHTTP(w, r.WithContext(ctxWithType))
+	}
 }
 
-func (Handler) Flatten(w http.ResponseWriter, r *http.Request) {
-	records := getRecordsFromCtx(r.Context())
-	fmt.Fprint(w, matrixToFlatString(records))
+// getNumericType gets the numeric type requested with the "type" query parameter, int is used by default
+func getNumericType(r *http.Request) (matrix.Type, error) {
+	typ := r.URL.Query().Get(numericTypeKey)
+	if typ == "" {
+		return matrix.Int, nil
+	}
+	return matrix.ParseType(typ)
 }
 
-func (Handler) Sum(w http.ResponseWriter, r *http.Request) {
-	records := getRecordsFromCtx(r.Context())
-	sum, err := sumIntMatrix(records)
+// formatMiddleware rejects the unknown response format requested with the "format" query parameter,
+// the invalid "indices" query parameter of the tables and the invalid "colormap" and "labels" ones of the heatmaps
+func formatMiddleware(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch f := format(r.URL.Query().Get(formatKey)); f {
+		case "", csvFormat, jsonFormat, mtxFormat, cooFormat, npyFormat, tableFormat, markdownFormat, latexFormat, htmlFormat, pngFormat:
+		default:
+			writeError(w, r, fmt.Errorf("%w: %q", errUnknownFormat, f))
+			return
+		}
+		if indices := r.URL.Query().Get(indicesKey); indices != "" {
+			if _, err := strconv.ParseBool(indices); err != nil {
+				writeError(w, r, fmt.Errorf("%w: %q", errInvalidIndices, indices))
+				return
+			}
+		}
+		if colorMap := r.URL.Query().Get(colorMapKey); colorMap != "" {
+			if _, err := matrix.ParseColorMap(colorMap); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		if labels := r.URL.Query().Get(labelsKey); labels != "" {
+			if _, err := strconv.ParseBool(labels); err != nil {
+				writeError(w, r, fmt.Errorf("%w: %q", errInvalidLabels, labels))
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// responseFormat gets the format requested with the "format" query parameter,
+// or the one accepted by the client when the parameter is empty. CSV is used by default
+func responseFormat(r *http.Request) format {
+	if f := format(r.URL.Query().Get(formatKey)); f != "" {
+		return f
+	}
+	switch {
+	case acceptsJSON(r):
+		return jsonFormat
+	case hasMediaType(r.Header.Get("Accept"), mtxContentType):
+		return mtxFormat
+	case hasMediaType(r.Header.Get("Accept"), cooContentType):
+		return cooFormat
+	case hasMediaType(r.Header.Get("Accept"), npyContentType):
+		return npyFormat
+	case hasMediaType(r.Header.Get("Accept"), pngContentType):
+		return pngFormat
+	}
+	return csvFormat
+}
+
+// limitsMiddleware bounds the request body size and passes the matrix limits to the readers
+func limitsMiddleware(handler http.Handler, l limits) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, l.MaxUploadSize)
+		ctxWithLimits := context.WithValue(r.Context(), limitsKey, l)
+		handler.ServeHTTP(w, r.WithContext(ctxWithLimits))
+	})
+}
+
+// deprecatedMiddleware marks the responses of the deprecated route and points the clients to its successor
+func deprecatedMiddleware(successor string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logWarn("deprecated route", r.URL.Path, "is requested, use", successor, "instead")
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		handler.ServeHTTP(w, r)
+	}
+}
+
+// openOperands opens both operands of the binary operation, the sparse files are kept sparse, see sparseRows
+func openOperands(w http.ResponseWriter, r *http.Request) (a, b matrix.RowReader, err error) {
+	switch {
+	case isJSONRequest(r):
+		matrixA, matrixB, err := readJSONOperands(w, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return matrixA.RowReader(), matrixB.RowReader(), nil
+	case isCsvRequest(r), isMtxRequest(r), isCooRequest(r), isNpyRequest(r), isXlsxRequest(r):
+		// the raw body holds only one matrix
+		writeError(w, r, errSingleCsvBody)
+		return nil, nil, errSingleCsvBody
+	}
+	a, err = openMultipartFile(w, r, multipartLeftOperandKey)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, err
 	}
-	fmt.Fprint(w, sum)
+	b, err = openMultipartFile(w, r, multipartRightOperandKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
 }
 
-func (Handler) Multiply(w http.ResponseWriter, r *http.Request) {
-	records := getRecordsFromCtx(r.Context())
-	sum, err := multiplyIntMatrix(records)
+func readAllRows(w http.ResponseWriter, r *http.Request, rows matrix.RowReader) (*matrix.Text, error) {
+	m, err := matrix.ReadAll(rows)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
+		return nil, err
 	}
+	return m, nil
+}
 
-	fmt.Fprint(w, sum)
+// isCsvRequest checks if the matrix is sent as the raw CSV request body
+func isCsvRequest(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Content-Type"), csvContentType)
 }
 
-func getRecordsFromCtx(ctx context.Context) [][]string {
-	return ctx.Value(recordsKey).([][]string)
+// isMtxRequest checks if the matrix is sent as the raw Matrix Market request body
+func isMtxRequest(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Content-Type"), mtxContentType)
 }
 
-func getRecordsMiddleware(handler http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		records, err := readMultipartCsvFile(w, r, multipartFileKey)
-		if err != nil {
-			// http.Error call inside readMultipartCsvFile
-			log.Println(err)
-			return
-		}
-		if !isMatrixSquare(records) {
-			http.Error(w, errNotSquareMatrix.Error(), http.StatusBadRequest)
-			return
+// isCooRequest checks if the sparse matrix is sent as the raw COO request body
+func isCooRequest(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Content-Type"), cooContentType)
+}
+
+// isNpyRequest checks if the matrix is sent as the raw NumPy request body
+func isNpyRequest(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Content-Type"), npyContentType)
+}
+
+// isXlsxRequest checks if the matrix is sent as the raw Excel workbook request body
+func isXlsxRequest(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Content-Type"), xlsxContentType)
+}
+
+// writeMatrix writes the matrix in the response format, see responseFormat
+func writeMatrix(w http.ResponseWriter, r *http.Request, m *matrix.Text) {
+	switch responseFormat(r) {
+	case jsonFormat:
+		writeJSON(w, newJSONMatrix(m))
+	case mtxFormat:
+		writeMtx(w, r, m)
+	case cooFormat:
+		writeSparse(w, r, m.Sparse())
+	case npyFormat:
+		writeNpy(w, r, []int{m.Rows(), m.Cols()}, m.Flatten())
+	case tableFormat, markdownFormat, latexFormat, htmlFormat:
+		writeTable(w, r, m)
+	case pngFormat:
+		writeHeatmap(w, r, m)
+	default:
+		fmt.Fprint(w, m.String())
+	}
+}
+
+// writeList writes the elements as JSON list, as 1xN Matrix Market, COO matrix or table, as 1-D NumPy array or as comma separated line
+func writeList(w http.ResponseWriter, r *http.Request, elems []string) {
+	switch responseFormat(r) {
+	case jsonFormat:
+		writeJSON(w, jsonResult{Result: newJSONList(elems)})
+	case mtxFormat, cooFormat, tableFormat, markdownFormat, latexFormat, htmlFormat, pngFormat:
+		writeRecords(w, r, [][]string{elems})
+	case npyFormat:
+		writeNpy(w, r, []int{len(elems)}, elems)
+	default:
+		fmt.Fprint(w, strings.Join(elems, ","))
+	}
+}
+
+// writeScalar writes the single value as JSON, as 1x1 Matrix Market, COO matrix or table, as 0-D NumPy array or as is
+func writeScalar(w http.ResponseWriter, r *http.Request, value string) {
+	switch responseFormat(r) {
+	case jsonFormat:
+		writeJSON(w, jsonResult{Result: newJSONValue(value)})
+	case mtxFormat, cooFormat, tableFormat, markdownFormat, latexFormat, htmlFormat, pngFormat:
+		writeRecords(w, r, [][]string{{value}})
+	case npyFormat:
+		writeNpy(w, r, nil, []string{value})
+	default:
+		fmt.Fprint(w, value)
+	}
+}
+
+func writeRecords(w http.ResponseWriter, r *http.Request, records [][]string) {
+	m, err := matrix.NewText(records)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeMatrix(w, r, m)
+}
+
+// writeMtx writes the matrix in the Matrix Market format, the non-numeric matrix is reported as error
+func writeMtx(w http.ResponseWriter, r *http.Request, m *matrix.Text) {
+	writeEncoded(w, r, mtxContentType, func(out io.Writer) error { return writeMatrixMarket(out, m) })
+}
+
+func writeSparseMtx(w http.ResponseWriter, r *http.Request, s *matrix.SparseText) {
+	writeEncoded(w, r, mtxContentType, func(out io.Writer) error { return writeSparseMatrixMarket(out, s) })
+}
+
+// writeNpy writes the elements as NumPy array of the given shape, the non-real matrix is reported as error
+func writeNpy(w http.ResponseWriter, r *http.Request, shape []int, elems []string) {
+	writeEncoded(w, r, npyContentType, func(out io.Writer) error { return writeNumPy(out, shape, elems) })
+}
+
+// writeTable writes the matrix as the human-readable table of the response format, see tableContentTypes
+func writeTable(w http.ResponseWriter, r *http.Request, m *matrix.Text) {
+	f := responseFormat(r)
+	writeEncoded(w, r, tableContentTypes[f], func(out io.Writer) error {
+		encoder := matrix.NewEncoder(out, matrix.Style(f))
+		// the value is validated by formatMiddleware
+		indices, _ := strconv.ParseBool(r.URL.Query().Get(indicesKey))
+		encoder.SetIndices(indices)
+		return encoder.Encode(m)
+	})
+}
+
+// writeHeatmap draws the matrix as the PNG heatmap with the color map and the labels of the query parameters
+func writeHeatmap(w http.ResponseWriter, r *http.Request, m *matrix.Text) {
+	writeEncoded(w, r, pngContentType, func(out io.Writer) error {
+		encoder := matrix.NewHeatmapEncoder(out)
+		// the values are validated by formatMiddleware
+		if colorMap := r.URL.Query().Get(colorMapKey); colorMap != "" {
+			encoder.SetColorMap(matrix.ColorMap(colorMap))
 		}
-		ctxWithRecords := context.WithValue(r.Context(), "records", records)
-		handler.ServeHTTP(w, r.WithContext(ctxWithRecords))
+		labels, _ := strconv.ParseBool(r.URL.Query().Get(labelsKey))
+		encoder.SetLabels(labels)
+		return encoder.Encode(m)
+	})
+}
+
+// writeEncoded buffers the output of write, so the error is reported before anything is sent
+func writeEncoded(w http.ResponseWriter, r *http.Request, contentType string, write func(out io.Writer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		writeError(w, r, err)
+		return
 	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
 }
 
-func readMultipartCsvFile(w http.ResponseWriter, r *http.Request, key string) ([][]string, error) {
+// openMultipartFile reads the file of the multipart form into memory, the form is already buffered by ParseMultipartForm
+func openMultipartFile(w http.ResponseWriter, r *http.Request, key string) (matrix.RowReader, error) {
 	file, fileheader, err := r.FormFile(key)
+	if errors.Is(err, http.ErrMissingFile) {
+		// the CSV can also be sent as a plain form field without filename, e.g. curl -F 'file=<matrix.csv'
+		if values := r.MultipartForm.Value[key]; len(values) > 0 {
+			return readCsvRows(w, r, strings.NewReader(values[0]))
+		}
+		err = fmt.Errorf("%w: %q", errMissingFile, key)
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return nil, err
 	}
 	defer file.Close()
 
 	// check the file extension
-	ext := filepath.Ext(fileheader.Filename)
-	if ext != csvExtension {
-		http.Error(w, errInvalidFileFormatCSV.Error(), http.StatusBadRequest)
-		return nil, errInvalidFileFormatCSV
+	switch filepath.Ext(fileheader.Filename) {
+	case csvExtension:
+		return readCsvRows(w, r, file)
+	case mtxExtension:
+		return openMtxRows(w, r, file)
+	case cooExtension:
+		return openCooRows(w, r, file)
+	case npyExtension, npzExtension:
+		return openNpyRows(w, r, file)
+	case xlsxExtension:
+		return openXlsxRows(w, r, file)
 	}
+	writeError(w, r, errInvalidFileFormat)
+	return nil, errInvalidFileFormat
+}
 
-	records, err := csv.NewReader(file).ReadAll()
+// readCsvRows reads the whole CSV, so the reader can be closed before the rows are read
+func readCsvRows(w http.ResponseWriter, r *http.Request, reader io.Reader) (matrix.RowReader, error) {
+	rows, err := openCsvRows(w, r, reader)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
 		return nil, err
 	}
+	m, err := readAllRows(w, r, rows)
+	if err != nil {
+		return nil, err
+	}
+	return m.RowReader(), nil
+}
+
+// checkShapeLimits checks the number of rows and columns against the limits, so the declared shape
+// can be rejected before the matrix is allocated
+func checkShapeLimits(rows, cols int, l limits) error {
+	if rows > l.MaxRows {
+		return fmt.Errorf("%w: more than %d rows, the max rows limit", errMatrixTooLarge, l.MaxRows)
+	}
+	if cols > l.MaxCols {
+		return fmt.Errorf("%w: more than %d columns, the max columns limit", errMatrixTooLarge, l.MaxCols)
+	}
+	return nil
+}
+
+// checkMatrixLimits checks every row of the matrix against the limits
+func checkMatrixLimits(m *matrix.Text, l limits) error {
+	for i := 0; i < m.Rows(); i++ {
+		if err := checkRowLimits(i+1, m.Row(i), l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRowLimits checks the row with the given 1-based number against the rows, columns and cell length limits
+func checkRowLimits(number int, row []string, l limits) error {
+	if err := checkShapeLimits(number, len(row), l); err != nil {
+		return err
+	}
+	for j := range row {
+		if len(row[j]) > l.MaxCellLength {
+			return newCellTooLongError(number, j+1, l)
+		}
+	}
+	return nil
+}
 
-	if records == nil {
-		http.Error(w, errEmptyRecord.Error(), http.StatusBadRequest)
-		return nil, errEmptyRecord
+// newCellTooLongError reports the element at the 1-based row and column, the value itself isn't reported, as it's too long
+func newCellTooLongError(row, col int, l limits) error {
+	return &matrix.CellError{
+		Err: fmt.Errorf("%w, the max cell length limit is %d bytes", errCellTooLong, l.MaxCellLength),
+		Row: row,
+		Col: col,
 	}
-	return records, nil
 }
@@ -2,19 +2,36 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+
+	"matrix/pkg/matrix"
 )
 
 func init() {
-	mux := NewHandler()
-	go http.ListenAndServe(":8081", mux)
+	mux := NewHandler(defaultConfig().limits)
+	// listen synchronously, so the requests sent by tests can't outrun the server start
+	listener, err := net.Listen("tcp", ":8081")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go http.Serve(listener, mux)
 }
 
+const (
+	metaTemplate = "testData %s #%d; when %s, then %s"
+	errTemplate  = "%s \n got = %v \n want = %v \n"
+)
+
 const (
 	unexpectedBody = "handler returned unexpected body: got %v want %v"
 	unexpectedCode = "handler returned unexpected code: got %v want %v"
@@ -24,6 +41,19 @@ const (
 	invalidCSVPath     = "testData/invalidCSV.csv"
 	emptyPath          = "testData/emptyFIle.csv"
 	notSquarePath      = "testData/notsquare.csv"
+	bigMatrixPath      = "testData/bigMatrix.csv"
+	int64OverflowPath  = "testData/int64Overflow.csv"
+	invertiblePath     = "testData/invertible.csv"
+	rectangular2x3Path = "testData/rectangular2x3.csv"
+	rectangular3x2Path = "testData/rectangular3x2.csv"
+	floatsPath         = "testData/floats.csv"
+	symmetricPath      = "testData/symmetric.mtx"
+	skewSymmetricPath  = "testData/skewSymmetric.mtx"
+	sparsePath         = "testData/sparse.coo"
+	npyPath            = "testData/matrix.npy"
+	fortranNpyPath     = "testData/fortran.npy"
+	npzPath            = "testData/matrix.npz"
+	xlsxPath           = "testData/workbook.xlsx"
 
 	defaultURL = "http://localhost:8081"
 )
@@ -33,6 +63,23 @@ func TestHandler_Echo(t *testing.T) {
 	okReq, writer := SetupRequest(validPath, url, t)
 	okReq.Header.Set("Content-Type", writer.FormDataContentType())
 
+	csvBodyReq := SetupCsvRequest(validPath, url, t)
+
+	emptyCsvBodyReq := SetupCsvRequest(emptyPath, url, t)
+
+	formFieldReq, writer := SetupFormFieldRequest(validPath, url, t)
+	formFieldReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	jsonResponseReq, writer := SetupRequest(validPath, url, t)
+	jsonResponseReq.Header.Set("Content-Type", writer.FormDataContentType())
+	jsonResponseReq.Header.Set("Accept", "application/json")
+
+	jsonBodyReq := SetupJSONRequest(`{"data":[[1,2,3],["4","5","6"],[7,8,9]]}`, url, t)
+
+	raggedJSONReq := SetupJSONRequest(`{"data":[[1,2,3],[4,5]]}`, url, t)
+
+	invalidJSONReq := SetupJSONRequest(`{"data":[[1,2,3]`, url, t)
+
 	wrongFormatReq, writer := SetupRequest(wrongExtensionPath, url, t)
 	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
 
@@ -45,6 +92,59 @@ func TestHandler_Echo(t *testing.T) {
 	notSquareReq, writer := SetupRequest(notSquarePath, url, t)
 	notSquareReq.Header.Set("Content-Type", writer.FormDataContentType())
 
+	mtxFileReq, writer := SetupRequest(symmetricPath, url, t)
+	mtxFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mtxBodyReq := SetupCsvRequest(skewSymmetricPath, url, t)
+	mtxBodyReq.Header.Set("Content-Type", mtxContentType)
+
+	invalidMtxReq := SetupJSONRequest("%%MatrixMarket matrix array real general\n2 2\n1\n2\n3\n", url, t)
+	invalidMtxReq.Header.Set("Content-Type", mtxContentType)
+
+	mtxResponseReq, writer := SetupRequest(validPath, url+"?format=mtx", t)
+	mtxResponseReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	unknownFormatReq, writer := SetupRequest(validPath, url+"?format=xml", t)
+	unknownFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	npyFileReq, writer := SetupRequest(npyPath, url, t)
+	npyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	npzFileReq, writer := SetupRequest(npzPath, url, t)
+	npzFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	npyBodyReq := SetupCsvRequest(fortranNpyPath, url, t)
+	npyBodyReq.Header.Set("Content-Type", npyContentType)
+
+	invalidNpyReq := SetupJSONRequest(string(newNpy("<c16", false, "(1, 1)", []complex128{1})), url, t)
+	invalidNpyReq.Header.Set("Content-Type", npyContentType)
+
+	npyResponseReq, writer := SetupRequest(rectangular2x3Path, url, t)
+	npyResponseReq.Header.Set("Content-Type", writer.FormDataContentType())
+	npyResponseReq.Header.Set("Accept", npyContentType)
+
+	markdownReq, writer := SetupRequest(rectangular2x3Path, url+"?format=markdown&indices=true", t)
+	markdownReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	invalidIndicesReq, writer := SetupRequest(validPath, url+"?format=table&indices=maybe", t)
+	invalidIndicesReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	pngReq, writer := SetupRequest(rectangular2x3Path, url+"?colormap=gray&labels=true", t)
+	pngReq.Header.Set("Content-Type", writer.FormDataContentType())
+	pngReq.Header.Set("Accept", pngContentType)
+
+	unknownColorMapReq, writer := SetupRequest(validPath, url+"?format=png&colormap=jet", t)
+	unknownColorMapReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	invalidLabelsReq, writer := SetupRequest(validPath, url+"?format=png&labels=maybe", t)
+	invalidLabelsReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	xlsxFileReq, writer := SetupRequest(xlsxPath, url, t)
+	xlsxFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mergedCellsReq := SetupCsvRequest(xlsxPath, url+"?sheet=Report", t)
+	mergedCellsReq.Header.Set("Content-Type", xlsxContentType)
+
 	type args struct {
 		req *http.Request
 	}
@@ -72,7 +172,7 @@ func TestHandler_Echo(t *testing.T) {
 			then: "error should be returned",
 
 			args:     args{req: wrongFormatReq},
-			wantBody: "invalid file format, only CSV allowed\n",
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
 			wantCode: http.StatusBadRequest,
 		},
 		{
@@ -94,12 +194,231 @@ func TestHandler_Echo(t *testing.T) {
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "echo endpoint unhappy path with not square matrix",
+			name: "echo endpoint happy path with not square matrix",
 			when: "the sent matrix is not square",
-			then: "error should be returned",
+			then: "same matrix should be returned",
 
 			args:     args{req: notSquareReq},
-			wantBody: "matrix should be square\n",
+			wantBody: "1,2,3\n4,5,6\n7,8,9\n10,11,12\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with JSON response",
+			when: "the client accepts JSON",
+			then: "same matrix should be returned as JSON",
+
+			args:     args{req: jsonResponseReq},
+			wantBody: `{"rows":3,"cols":3,"data":[[1,2,3],[4,5,6],[7,8,9]]}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with JSON body",
+			when: "the matrix is sent as JSON",
+			then: "same matrix should be returned",
+
+			args:     args{req: jsonBodyReq},
+			wantBody: "1,2,3\n4,5,6\n7,8,9\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with ragged JSON matrix",
+			when: "the rows of JSON matrix have different length",
+			then: "error with the offending row should be returned",
+
+			args:     args{req: raggedJSONReq},
+			wantBody: "all matrix rows should have the same number of elements: row 2 has 2 elements, but row 1 has 3\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint unhappy path with invalid JSON",
+			when: "the JSON body is malformed",
+			then: "error should be returned",
+
+			args:     args{req: invalidJSONReq},
+			wantBody: "invalid JSON body: unexpected EOF\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with raw CSV body",
+			when: "the matrix is sent as text/csv body",
+			then: "same matrix should be returned",
+
+			args:     args{req: csvBodyReq},
+			wantBody: "1,2,3\n4,5,6\n7,8,9\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with empty raw CSV body",
+			when: "the text/csv body is empty",
+			then: "error should be returned",
+
+			args:     args{req: emptyCsvBodyReq},
+			wantBody: "matrix shouldn't be empty\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with form field",
+			when: "the matrix is sent as multipart form field without filename",
+			then: "same matrix should be returned without the extension check",
+
+			args:     args{req: formFieldReq},
+			wantBody: "1,2,3\n4,5,6\n7,8,9\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with Matrix Market file",
+			when: "the lower triangle of symmetric matrix is sent as .mtx file",
+			then: "the whole matrix should be returned",
+
+			args:     args{req: mtxFileReq},
+			wantBody: "2,-1,0\n-1,0,-1\n0,-1,2\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with raw Matrix Market body",
+			when: "the skew-symmetric matrix is sent as text/x-matrix-market body",
+			then: "the whole matrix should be returned",
+
+			args:     args{req: mtxBodyReq},
+			wantBody: "0,-1.5,2\n1.5,0,-3\n-2,3,0\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with invalid Matrix Market body",
+			when: "the array matrix has less entries than declared",
+			then: "error with the line should be returned",
+
+			args:     args{req: invalidMtxReq},
+			wantBody: "invalid Matrix Market file: line 5: the entry at row 2, column 2 is missing\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with Matrix Market response",
+			when: "the Matrix Market format is requested",
+			then: "the matrix should be returned as integer array",
+
+			args:     args{req: mtxResponseReq},
+			wantBody: "%%MatrixMarket matrix array integer general\n3 3\n1\n4\n7\n2\n5\n8\n3\n6\n9\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with unknown format",
+			when: "the unknown response format is requested",
+			then: "error should be returned",
+
+			args:     args{req: unknownFormatReq},
+			wantBody: "unknown format, csv, json, mtx, coo, npy, table, markdown, latex, html and png allowed: \"xml\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with NumPy file",
+			when: "the int64 array is sent as .npy file",
+			then: "the matrix should be returned",
+
+			args:     args{req: npyFileReq},
+			wantBody: "1,2,3\n4,5,6\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with NumPy archive",
+			when: "the archive with one array is sent as .npz file",
+			then: "the matrix should be returned",
+
+			args:     args{req: npzFileReq},
+			wantBody: "1,2\n3,4\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with raw NumPy body",
+			when: "the float32 array in Fortran order is sent as application/x-npy body",
+			then: "the matrix should be returned row by row",
+
+			args:     args{req: npyBodyReq},
+			wantBody: "1.5,-2\n0.25,4\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with unsupported NumPy dtype",
+			when: "the complex array is sent",
+			then: "error should be returned",
+
+			args:     args{req: invalidNpyReq},
+			wantBody: "invalid NumPy array: dtype \"<c16\" isn't supported, only integers and floats allowed\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with NumPy response",
+			when: "the NumPy format is accepted",
+			then: "the matrix should be returned as int64 array",
+
+			args:     args{req: npyResponseReq},
+			wantBody: string(newNpy("<i8", false, "(2, 3)", []int64{1, 2, 3, 4, 5, 6})),
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint happy path with Markdown response",
+			when: "the Markdown table with indices is requested",
+			then: "the matrix should be returned as aligned Markdown table",
+
+			args: args{req: markdownReq},
+			wantBody: "|     |   1 |   2 |   3 |\n" +
+				"| --: | --: | --: | --: |\n" +
+				"|   1 |   1 |   2 |   3 |\n" +
+				"|   2 |   4 |   5 |   6 |\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with invalid indices",
+			when: "the indices parameter isn't boolean",
+			then: "error should be returned",
+
+			args:     args{req: invalidIndicesReq},
+			wantBody: "invalid indices, true or false allowed: \"maybe\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with PNG response",
+			when: "the PNG image is accepted with the gray color map and labels",
+			then: "the matrix should be returned as the heatmap",
+
+			args:     args{req: pngReq},
+			wantBody: newHeatmap(t, [][]string{{"1", "2", "3"}, {"4", "5", "6"}}, matrix.Gray, true),
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with unknown color map",
+			when: "the color map doesn't exist",
+			then: "error should be returned",
+
+			args:     args{req: unknownColorMapReq},
+			wantBody: "unknown color map, viridis, gray, hot and coolwarm allowed: \"jet\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint unhappy path with invalid labels",
+			when: "the labels parameter isn't boolean",
+			then: "error should be returned",
+
+			args:     args{req: invalidLabelsReq},
+			wantBody: "invalid labels, true or false allowed: \"maybe\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "echo endpoint happy path with Excel file",
+			when: "the workbook is sent as .xlsx file without sheet and range",
+			then: "the filled cells of the first sheet should be returned",
+
+			args:     args{req: xlsxFileReq},
+			wantBody: "1,2,3\n4,5,6\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "echo endpoint unhappy path with merged Excel cells",
+			when: "the selected sheet has merged cells",
+			then: "error with the merged range should be returned",
+
+			args:     args{req: mergedCellsReq},
+			wantBody: "merged cells aren't allowed in matrix: A5:B5\n",
 			wantCode: http.StatusBadRequest,
 		},
 	}
@@ -165,7 +484,7 @@ func TestHandler_Invert(t *testing.T) {
 			then: "error should be returned",
 
 			args:     args{req: wrongFormatReq},
-			wantBody: "invalid file format, only CSV allowed\n",
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
 			wantCode: http.StatusBadRequest,
 		},
 		{
@@ -208,11 +527,31 @@ func TestHandler_Invert(t *testing.T) {
 	}
 }
 
-func TestHandler_Flatten(t *testing.T) {
-	url := fmt.Sprintf("%s%s", defaultURL, "/flatten")
+func TestHandler_InvertDeprecation(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/invert")
+	req, writer := SetupRequest(validPath, url, t)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Header.Get("Deprecation"); got != "true" {
+		t.Errorf("handler returned unexpected Deprecation header: got %v want %v", got, "true")
+	}
+	if got, want := resp.Header.Get("Link"), `</transpose>; rel="successor-version"`; got != want {
+		t.Errorf("handler returned unexpected Link header: got %v want %v", got, want)
+	}
+}
+
+func TestHandler_Transpose(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/transpose")
 	okReq, writer := SetupRequest(validPath, url, t)
 	okReq.Header.Set("Content-Type", writer.FormDataContentType())
 
+	rectangularReq, writer := SetupRequest(rectangular2x3Path, url, t)
+	rectangularReq.Header.Set("Content-Type", writer.FormDataContentType())
+
 	wrongFormatReq, writer := SetupRequest(wrongExtensionPath, url, t)
 	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
 
@@ -222,6 +561,21 @@ func TestHandler_Flatten(t *testing.T) {
 	emptyFileReq, writer := SetupRequest(emptyPath, url, t)
 	emptyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
 
+	cooFileReq, writer := SetupRequest(sparsePath, url, t)
+	cooFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	hugeCooReq := SetupJSONRequest("100000,100000\n1,99999,5\n100000,1,-2\n", url+"?format=coo", t)
+	hugeCooReq.Header.Set("Content-Type", cooContentType)
+
+	hugeCsvResponseReq := SetupJSONRequest("100000,100000\n1,99999,5\n", url, t)
+	hugeCsvResponseReq.Header.Set("Content-Type", cooContentType)
+
+	sparseStorageReq, writer := SetupRequest(validPath, url+"?storage=sparse&format=coo", t)
+	sparseStorageReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	unknownStorageReq, writer := SetupRequest(validPath, url+"?storage=compressed", t)
+	unknownStorageReq.Header.Set("Content-Type", writer.FormDataContentType())
+
 	type args struct {
 		req *http.Request
 	}
@@ -235,25 +589,34 @@ func TestHandler_Flatten(t *testing.T) {
 		wantCode int
 	}{
 		{
-			name: "flatten endpoint happy path",
+			name: "transpose endpoint happy path",
 			when: "everything is OK",
-			then: "flatten matrix should be returned",
+			then: "transposed matrix should be returned",
 
 			args:     args{req: okReq},
-			wantBody: "1,2,3,4,5,6,7,8,9",
+			wantBody: "1,4,7\n2,5,8\n3,6,9\n",
 			wantCode: http.StatusOK,
 		},
 		{
-			name: "flatten endpoint unhappy path with wrong format",
+			name: "transpose endpoint happy path with rectangular matrix",
+			when: "the sent matrix is not square",
+			then: "transposed matrix should be returned",
+
+			args:     args{req: rectangularReq},
+			wantBody: "1,4\n2,5\n3,6\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "transpose endpoint unhappy path with wrong format",
 			when: "wrong file format sent",
 			then: "error should be returned",
 
 			args:     args{req: wrongFormatReq},
-			wantBody: "invalid file format, only CSV allowed\n",
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "flatten endpoint unhappy path with invalid csv",
+			name: "transpose endpoint unhappy path with invalid csv",
 			when: "invalid csv file sent",
 			then: "error should be returned",
 
@@ -262,7 +625,7 @@ func TestHandler_Flatten(t *testing.T) {
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "flatten endpoint unhappy path with empty file",
+			name: "transpose endpoint unhappy path with empty file",
 			when: "request sent without file",
 			then: "error should be returned",
 
@@ -270,6 +633,51 @@ func TestHandler_Flatten(t *testing.T) {
 			wantBody: "matrix shouldn't be empty\n",
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name: "transpose endpoint happy path with COO file",
+			when: "the small sparse matrix is sent as .coo file",
+			then: "transposed matrix should be returned as dense CSV",
+
+			args:     args{req: cooFileReq},
+			wantBody: "2,0,0\n0,0,-1\n0,0,0\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "transpose endpoint happy path with huge sparse matrix",
+			when: "the matrix over the dense limits is sent as COO body and the COO response is requested",
+			then: "transposed triplets should be returned",
+
+			args:     args{req: hugeCooReq},
+			wantBody: "100000,100000\n1,100000,-2\n99999,1,5\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "transpose endpoint unhappy path with huge sparse matrix as CSV",
+			when: "the matrix over the dense limits is sent and the dense response is requested",
+			then: "error should be returned",
+
+			args:     args{req: hugeCsvResponseReq},
+			wantBody: "matrix is too large: more than 10000 rows, the max rows limit, request the sparse result with ?format=coo or ?format=mtx\n",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "transpose endpoint happy path with sparse storage",
+			when: "the sparse storage is forced for the dense CSV matrix",
+			then: "transposed triplets should be returned",
+
+			args:     args{req: sparseStorageReq},
+			wantBody: "3,3\n1,1,1\n1,2,4\n1,3,7\n2,1,2\n2,2,5\n2,3,8\n3,1,3\n3,2,6\n3,3,9\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "transpose endpoint unhappy path with unknown storage",
+			when: "the storage doesn't exist",
+			then: "error should be returned",
+
+			args:     args{req: unknownStorageReq},
+			wantBody: "unknown storage, auto, dense and sparse allowed: \"compressed\"\n",
+			wantCode: http.StatusBadRequest,
+		},
 	}
 	for _, testCase := range tests {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -292,19 +700,22 @@ func TestHandler_Flatten(t *testing.T) {
 	}
 }
 
-func TestHandler_Sum(t *testing.T) {
-	url := fmt.Sprintf("%s%s", defaultURL, "/sum")
-	okReq, writer := SetupRequest(validPath, url, t)
+func TestHandler_Inverse(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/inverse")
+	okReq, writer := SetupRequest(invertiblePath, url, t)
 	okReq.Header.Set("Content-Type", writer.FormDataContentType())
 
+	singularReq, writer := SetupRequest(validPath, url, t)
+	singularReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	int64Req, writer := SetupRequest(invertiblePath, url+"?type=int64", t)
+	int64Req.Header.Set("Content-Type", writer.FormDataContentType())
+
 	wrongFormatReq, writer := SetupRequest(wrongExtensionPath, url, t)
 	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
 
-	invalidCsvReq, writer := SetupRequest(invalidCSVPath, url, t)
-	invalidCsvReq.Header.Set("Content-Type", writer.FormDataContentType())
-
-	emptyFileReq, writer := SetupRequest(emptyPath, url, t)
-	emptyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+	notSquareReq, writer := SetupRequest(notSquarePath, url, t)
+	notSquareReq.Header.Set("Content-Type", writer.FormDataContentType())
 
 	type args struct {
 		req *http.Request
@@ -319,39 +730,48 @@ func TestHandler_Sum(t *testing.T) {
 		wantCode int
 	}{
 		{
-			name: "sum endpoint happy path",
-			when: "everything is OK",
-			then: "sum of all matrix element should be returned",
+			name: "inverse endpoint happy path",
+			when: "the matrix is invertible",
+			then: "inverse matrix should be returned",
 
 			args:     args{req: okReq},
-			wantBody: "45",
+			wantBody: "3/4,1/2,1/4\n1/2,1,1/2\n1/4,1/2,3/4\n",
 			wantCode: http.StatusOK,
 		},
 		{
-			name: "sum endpoint unhappy path with wrong format",
-			when: "wrong file format sent",
+			name: "inverse endpoint unhappy path with singular matrix",
+			when: "the matrix has no inverse",
 			then: "error should be returned",
 
-			args:     args{req: wrongFormatReq},
-			wantBody: "invalid file format, only CSV allowed\n",
+			args:     args{req: singularReq},
+			wantBody: "matrix is singular and has no inverse\n",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "inverse endpoint unhappy path with int64 type",
+			when: "the integer type without exact division is requested",
+			then: "error should be returned",
+
+			args:     args{req: int64Req},
+			wantBody: "operation isn't supported for the numeric type: inverse needs the exact division, which the integer elements don't have\n",
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "sum endpoint unhappy path with invalid csv",
-			when: "invalid csv file sent",
+			name: "inverse endpoint unhappy path with wrong format",
+			when: "wrong file format sent",
 			then: "error should be returned",
 
-			args:     args{req: invalidCsvReq},
-			wantBody: "record on line 2: wrong number of fields\n",
+			args:     args{req: wrongFormatReq},
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "sum endpoint unhappy path with empty file",
-			when: "request sent without file",
+			name: "inverse endpoint unhappy path with not square matrix",
+			when: "the sent matrix is not square",
 			then: "error should be returned",
 
-			args:     args{req: emptyFileReq},
-			wantBody: "matrix shouldn't be empty\n",
+			args:     args{req: notSquareReq},
+			wantBody: "matrix should be square\n",
 			wantCode: http.StatusBadRequest,
 		},
 	}
@@ -376,11 +796,15 @@ func TestHandler_Sum(t *testing.T) {
 	}
 }
 
-func TestHandler_Multiply(t *testing.T) {
-	url := fmt.Sprintf("%s%s", defaultURL, "/multiply")
+func TestHandler_Flatten(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/flatten")
 	okReq, writer := SetupRequest(validPath, url, t)
 	okReq.Header.Set("Content-Type", writer.FormDataContentType())
 
+	jsonResponseReq, writer := SetupRequest(validPath, url, t)
+	jsonResponseReq.Header.Set("Content-Type", writer.FormDataContentType())
+	jsonResponseReq.Header.Set("Accept", "application/json")
+
 	wrongFormatReq, writer := SetupRequest(wrongExtensionPath, url, t)
 	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
 
@@ -403,25 +827,25 @@ func TestHandler_Multiply(t *testing.T) {
 		wantCode int
 	}{
 		{
-			name: "multiply endpoint happy path",
+			name: "flatten endpoint happy path",
 			when: "everything is OK",
-			then: "multiplication of all matrix element should be returned",
+			then: "flatten matrix should be returned",
 
 			args:     args{req: okReq},
-			wantBody: "362880",
+			wantBody: "1,2,3,4,5,6,7,8,9",
 			wantCode: http.StatusOK,
 		},
 		{
-			name: "multiply endpoint unhappy path with wrong format",
+			name: "flatten endpoint unhappy path with wrong format",
 			when: "wrong file format sent",
 			then: "error should be returned",
 
 			args:     args{req: wrongFormatReq},
-			wantBody: "invalid file format, only CSV allowed\n",
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "multiply endpoint unhappy path with invalid csv",
+			name: "flatten endpoint unhappy path with invalid csv",
 			when: "invalid csv file sent",
 			then: "error should be returned",
 
@@ -430,7 +854,7 @@ func TestHandler_Multiply(t *testing.T) {
 			wantCode: http.StatusBadRequest,
 		},
 		{
-			name: "multiply endpoint unhappy path with empty file",
+			name: "flatten endpoint unhappy path with empty file",
 			when: "request sent without file",
 			then: "error should be returned",
 
@@ -438,6 +862,15 @@ func TestHandler_Multiply(t *testing.T) {
 			wantBody: "matrix shouldn't be empty\n",
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name: "flatten endpoint happy path with JSON response",
+			when: "the client accepts JSON",
+			then: "flatten matrix should be returned as JSON list",
+
+			args:     args{req: jsonResponseReq},
+			wantBody: `{"result":[1,2,3,4,5,6,7,8,9]}` + "\n",
+			wantCode: http.StatusOK,
+		},
 	}
 	for _, testCase := range tests {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -460,14 +893,1147 @@ func TestHandler_Multiply(t *testing.T) {
 	}
 }
 
-func SetupRequest(filePath string, url string, t *testing.T) (*http.Request, *multipart.Writer) {
-	body := new(bytes.Buffer)
-	writer := multipart.NewWriter(body)
-	formFile, err := writer.CreateFormFile(multipartFileKey, filePath)
-	if err != nil {
-		t.Fatal(err)
-	}
-	file, err := os.Open(filePath)
+func TestHandler_Sum(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/sum")
+	okReq, writer := SetupRequest(validPath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	jsonReq := SetupJSONRequest(`{"data":[[1,2],[3,4]]}`, url, t)
+	jsonReq.Header.Set("Accept", "application/json")
+
+	bigMatrixReq, writer := SetupRequest(bigMatrixPath, url, t)
+	bigMatrixReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	overflowReq, writer := SetupRequest(int64OverflowPath, url, t)
+	overflowReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	int64OverflowReq, writer := SetupRequest(int64OverflowPath, url+"?type=int64", t)
+	int64OverflowReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rectangularReq, writer := SetupRequest(rectangular2x3Path, url, t)
+	rectangularReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	floatReq, writer := SetupRequest(floatsPath, url+"?type=float", t)
+	floatReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	notIntReq, writer := SetupRequest(floatsPath, url, t)
+	notIntReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mtxReq, writer := SetupRequest(skewSymmetricPath, url+"?type=float&format=mtx", t)
+	mtxReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	hugeCooReq := SetupJSONRequest("100000,100000\n1,99999,5\n100000,1,-2\n", url, t)
+	hugeCooReq.Header.Set("Content-Type", cooContentType)
+
+	npyReq, writer := SetupRequest(fortranNpyPath, url+"?type=float&format=npy", t)
+	npyReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	latexReq, writer := SetupRequest(validPath, url+"?format=latex", t)
+	latexReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	xlsxReq, writer := SetupRequest(xlsxPath, url+"?type=float&sheet=Report&range=B2:B3", t)
+	xlsxReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	emptyCellsReq, writer := SetupRequest(xlsxPath, url+"?sheet=Report&range=B2:B4", t)
+	emptyCellsReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	complexReq := SetupJSONRequest(`{"data":[["1+2i","3"],["-1i",0.5]]}`, url+"?type=complex", t)
+
+	unknownTypeReq, writer := SetupRequest(validPath, url+"?type=quaternion", t)
+	unknownTypeReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	wrongFormatReq, writer := SetupRequest(wrongExtensionPath, url, t)
+	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	invalidCsvReq, writer := SetupRequest(invalidCSVPath, url, t)
+	invalidCsvReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	emptyFileReq, writer := SetupRequest(emptyPath, url, t)
+	emptyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "sum endpoint happy path",
+			when: "everything is OK",
+			then: "sum of all matrix element should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "45",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with bigger matrix",
+			when: "the 5x5 matrix is sent",
+			then: "sum of all matrix element should be returned",
+
+			args:     args{req: bigMatrixReq},
+			wantBody: "1146",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with big numbers",
+			when: "the result doesn't fit into int64",
+			then: "the exact sum of all matrix element should be returned",
+
+			args:     args{req: overflowReq},
+			wantBody: "9223372036854775808",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint unhappy path with int64 overflow",
+			when: "the int64 type is requested and the result doesn't fit into int64",
+			then: "error should be returned instead of the wrapped sum",
+
+			args:     args{req: int64OverflowReq},
+			wantBody: matrix.ErrOverflow.Error() + "\n",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "sum endpoint happy path with rectangular matrix",
+			when: "the sent matrix is not square",
+			then: "sum of all matrix element should be returned",
+
+			args:     args{req: rectangularReq},
+			wantBody: "21",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with float type",
+			when: "the float type is requested",
+			then: "float sum of all matrix element should be returned",
+
+			args:     args{req: floatReq},
+			wantBody: "2003.75",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint unhappy path with not integer elements",
+			when: "the matrix consists floats, but the type isn't specified",
+			then: "error should be returned",
+
+			args:     args{req: notIntReq},
+			wantBody: "only integers allowed in matrix: \"1.5\" at row 1, column 1; \"2.5\" at row 1, column 2; \"-0.25\" at row 2, column 1; \"2e3\" at row 2, column 2\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "sum endpoint happy path with complex type",
+			when: "the complex type is requested",
+			then: "complex sum of all matrix element should be returned",
+
+			args:     args{req: complexReq},
+			wantBody: "4.5+1i",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint unhappy path with unknown type",
+			when: "the unknown numeric type is requested",
+			then: "error should be returned",
+
+			args:     args{req: unknownTypeReq},
+			wantBody: "unknown numeric type, int, int64, bigint, rational, decimal, float and complex allowed: \"quaternion\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "sum endpoint unhappy path with wrong format",
+			when: "wrong file format sent",
+			then: "error should be returned",
+
+			args:     args{req: wrongFormatReq},
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "sum endpoint unhappy path with invalid csv",
+			when: "invalid csv file sent",
+			then: "error should be returned",
+
+			args:     args{req: invalidCsvReq},
+			wantBody: "record on line 2: wrong number of fields\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "sum endpoint unhappy path with empty file",
+			when: "request sent without file",
+			then: "error should be returned",
+
+			args:     args{req: emptyFileReq},
+			wantBody: "matrix shouldn't be empty\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "sum endpoint happy path with JSON",
+			when: "the matrix is sent as JSON and the client accepts JSON",
+			then: "sum of all matrix element should be returned as JSON",
+
+			args:     args{req: jsonReq},
+			wantBody: `{"result":10}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with Matrix Market",
+			when: "the skew-symmetric matrix is sent and the Matrix Market response is requested",
+			then: "the zero sum should be returned as 1x1 coordinate matrix without entries",
+
+			args:     args{req: mtxReq},
+			wantBody: "%%MatrixMarket matrix coordinate integer general\n1 1 0\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with huge sparse matrix",
+			when: "the matrix over the dense limits is sent as COO body",
+			then: "the sum of the non-zero elements should be returned",
+
+			args:     args{req: hugeCooReq},
+			wantBody: "3",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with NumPy",
+			when: "the float32 array is sent and the NumPy response is requested",
+			then: "the sum should be returned as 0-D float64 array",
+
+			args:     args{req: npyReq},
+			wantBody: string(newNpy("<f8", false, "()", []float64{3.75})),
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with LaTeX",
+			when: "the LaTeX table is requested",
+			then: "the sum should be returned as 1x1 tabular",
+
+			args:     args{req: latexReq},
+			wantBody: "\\begin{tabular}{r}\n45 \\\\\n\\end{tabular}\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint happy path with Excel range",
+			when: "the sheet and the range of the workbook are selected",
+			then: "the sum of the selected cells should be returned",
+
+			args:     args{req: xlsxReq},
+			wantBody: "30.5",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "sum endpoint unhappy path with empty Excel cells",
+			when: "the selected range has the empty cell",
+			then: "error with the cell name should be returned",
+
+			args:     args{req: emptyCellsReq},
+			wantBody: "empty cells aren't allowed in matrix: B4\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Multiply(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/multiply")
+	okReq, writer := SetupRequest(validPath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	bigMatrixReq, writer := SetupRequest(bigMatrixPath, url, t)
+	bigMatrixReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	wrongFormatReq, writer := SetupRequest(wrongExtensionPath, url, t)
+	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	invalidCsvReq, writer := SetupRequest(invalidCSVPath, url, t)
+	invalidCsvReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	emptyFileReq, writer := SetupRequest(emptyPath, url, t)
+	emptyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "multiply endpoint happy path",
+			when: "everything is OK",
+			then: "multiplication of all matrix element should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "362880",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "multiply endpoint happy path with big numbers",
+			when: "the result doesn't fit into int64",
+			then: "the exact multiplication of all matrix element should be returned",
+
+			args:     args{req: bigMatrixReq},
+			wantBody: "3061742688298410699977991477264384000000",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "multiply endpoint unhappy path with wrong format",
+			when: "wrong file format sent",
+			then: "error should be returned",
+
+			args:     args{req: wrongFormatReq},
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "multiply endpoint unhappy path with invalid csv",
+			when: "invalid csv file sent",
+			then: "error should be returned",
+
+			args:     args{req: invalidCsvReq},
+			wantBody: "record on line 2: wrong number of fields\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "multiply endpoint unhappy path with empty file",
+			when: "request sent without file",
+			then: "error should be returned",
+
+			args:     args{req: emptyFileReq},
+			wantBody: "matrix shouldn't be empty\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Determinant(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/determinant")
+	okReq, writer := SetupRequest(bigMatrixPath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	singularReq, writer := SetupRequest(validPath, url, t)
+	singularReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	notSquareReq, writer := SetupRequest(notSquarePath, url, t)
+	notSquareReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	emptyFileReq, writer := SetupRequest(emptyPath, url, t)
+	emptyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "determinant endpoint happy path",
+			when: "everything is OK",
+			then: "determinant of the matrix should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "386694622",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "determinant endpoint happy path with singular matrix",
+			when: "the matrix is singular",
+			then: "zero should be returned",
+
+			args:     args{req: singularReq},
+			wantBody: "0",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "determinant endpoint unhappy path with not square matrix",
+			when: "the sent matrix is not square",
+			then: "error should be returned",
+
+			args:     args{req: notSquareReq},
+			wantBody: "matrix should be square\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "determinant endpoint unhappy path with empty file",
+			when: "request sent without file",
+			then: "error should be returned",
+
+			args:     args{req: emptyFileReq},
+			wantBody: "matrix shouldn't be empty\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Trace(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/trace")
+	okReq, writer := SetupRequest(bigMatrixPath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	notSquareReq, writer := SetupRequest(notSquarePath, url, t)
+	notSquareReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	emptyFileReq, writer := SetupRequest(emptyPath, url, t)
+	emptyFileReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "trace endpoint happy path",
+			when: "everything is OK",
+			then: "sum of the main diagonal should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "231",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "trace endpoint unhappy path with not square matrix",
+			when: "the sent matrix is not square",
+			then: "error should be returned",
+
+			args:     args{req: notSquareReq},
+			wantBody: "matrix should be square\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "trace endpoint unhappy path with empty file",
+			when: "request sent without file",
+			then: "error should be returned",
+
+			args:     args{req: emptyFileReq},
+			wantBody: "matrix shouldn't be empty\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_MatMul(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/matmul")
+	okReq, writer := SetupOperandsRequest(rectangular2x3Path, rectangular3x2Path, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	csvBodyReq := SetupCsvRequest(validPath, url, t)
+
+	mismatchReq, writer := SetupOperandsRequest(rectangular2x3Path, rectangular2x3Path, url, t)
+	mismatchReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	// the single operand is sent under the "file" key, so both "a" and "b" are missing
+	missingOperandReq, writer := SetupRequest(validPath, url, t)
+	missingOperandReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	wrongFormatReq, writer := SetupOperandsRequest(validPath, wrongExtensionPath, url, t)
+	wrongFormatReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	cooReq, writer := SetupOperandsRequest(sparsePath, sparsePath, url+"?format=coo", t)
+	cooReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "matmul endpoint happy path",
+			when: "everything is OK",
+			then: "matrix product should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "58,64\n139,154\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "matmul endpoint unhappy path with dimensions mismatch",
+			when: "the inner dimensions of the operands don't match",
+			then: "error with both shapes should be returned",
+
+			args:     args{req: mismatchReq},
+			wantBody: "matrix dimensions mismatch: can't multiply 2x3 matrix by 2x3 matrix, columns of the first should match rows of the second\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "matmul endpoint unhappy path with missing operand",
+			when: "the operand isn't sent",
+			then: "error should be returned",
+
+			args:     args{req: missingOperandReq},
+			wantBody: "no such file in the multipart form: \"a\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "matmul endpoint unhappy path with wrong format",
+			when: "wrong file format sent",
+			then: "error should be returned",
+
+			args:     args{req: wrongFormatReq},
+			wantBody: "invalid file format, only CSV, Matrix Market, COO, NumPy and Excel allowed\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "matmul endpoint unhappy path with raw CSV body",
+			when: "the single matrix is sent as text/csv body",
+			then: "error should be returned",
+
+			args:     args{req: csvBodyReq},
+			wantBody: "binary operations need two matrices, send them as multipart form or JSON\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "matmul endpoint happy path with COO files",
+			when: "both operands are sparse and the COO response is requested",
+			then: "the non-zero elements of the product should be returned",
+
+			args:     args{req: cooReq},
+			wantBody: "3,3\n1,1,4\n",
+			wantCode: http.StatusOK,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Add(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/add")
+	okReq, writer := SetupOperandsRequest(validPath, invertiblePath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mismatchReq, writer := SetupOperandsRequest(validPath, rectangular2x3Path, url, t)
+	mismatchReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "add endpoint happy path",
+			when: "everything is OK",
+			then: "element-wise sum should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "3,1,3\n3,7,5\n7,7,11\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "add endpoint unhappy path with dimensions mismatch",
+			when: "the shapes of the operands differ",
+			then: "error with both shapes should be returned",
+
+			args:     args{req: mismatchReq},
+			wantBody: "matrix dimensions mismatch: can't combine 3x3 matrix with 2x3 matrix element-wise, shapes should be equal\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Subtract(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/subtract")
+	okReq, writer := SetupOperandsRequest(validPath, invertiblePath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mismatchReq, writer := SetupOperandsRequest(validPath, rectangular2x3Path, url, t)
+	mismatchReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "subtract endpoint happy path",
+			when: "everything is OK",
+			then: "element-wise difference should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "-1,3,3\n5,3,7\n7,9,7\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "subtract endpoint unhappy path with dimensions mismatch",
+			when: "the shapes of the operands differ",
+			then: "error with both shapes should be returned",
+
+			args:     args{req: mismatchReq},
+			wantBody: "matrix dimensions mismatch: can't combine 3x3 matrix with 2x3 matrix element-wise, shapes should be equal\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Hadamard(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/hadamard")
+	okReq, writer := SetupOperandsRequest(validPath, invertiblePath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mismatchReq, writer := SetupOperandsRequest(validPath, rectangular2x3Path, url, t)
+	mismatchReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "hadamard endpoint happy path",
+			when: "everything is OK",
+			then: "element-wise product should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "2,-2,0\n-4,10,-6\n0,-8,18\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "hadamard endpoint unhappy path with dimensions mismatch",
+			when: "the shapes of the operands differ",
+			then: "error with both shapes should be returned",
+
+			args:     args{req: mismatchReq},
+			wantBody: "matrix dimensions mismatch: can't combine 3x3 matrix with 2x3 matrix element-wise, shapes should be equal\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Divide(t *testing.T) {
+	url := fmt.Sprintf("%s%s", defaultURL, "/divide")
+	okReq, writer := SetupOperandsRequest(invertiblePath, validPath, url, t)
+	okReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	jsonReq := SetupJSONRequest(`{"a":{"data":[[1,2]]},"b":{"data":[[4,"3"]]}}`, url, t)
+	jsonReq.Header.Set("Accept", "application/json")
+
+	missingOperandJSONReq := SetupJSONRequest(`{"a":{"data":[[1,2]]}}`, url, t)
+
+	divisionByZeroReq, writer := SetupOperandsRequest(validPath, invertiblePath, url, t)
+	divisionByZeroReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	mismatchReq, writer := SetupOperandsRequest(validPath, rectangular2x3Path, url, t)
+	mismatchReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantBody string
+		wantCode int
+	}{
+		{
+			name: "divide endpoint happy path",
+			when: "everything is OK",
+			then: "element-wise quotient should be returned",
+
+			args:     args{req: okReq},
+			wantBody: "2,-1/2,0\n-1/4,2/5,-1/6\n0,-1/8,2/9\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "divide endpoint unhappy path with division by zero",
+			when: "the divisor matrix has zero element",
+			then: "error with the cell coordinates should be returned",
+
+			args:     args{req: divisionByZeroReq},
+			wantBody: "division by zero: \"0\" at row 1, column 3 of matrix b; \"0\" at row 3, column 1 of matrix b\n",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name: "divide endpoint unhappy path with dimensions mismatch",
+			when: "the shapes of the operands differ",
+			then: "error with both shapes should be returned",
+
+			args:     args{req: mismatchReq},
+			wantBody: "matrix dimensions mismatch: can't combine 3x3 matrix with 2x3 matrix element-wise, shapes should be equal\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "divide endpoint happy path with JSON",
+			when: "the operands are sent as JSON and the client accepts JSON",
+			then: "element-wise quotient should be returned as JSON, with fractions as strings",
+
+			args:     args{req: jsonReq},
+			wantBody: `{"rows":1,"cols":2,"data":[["1/4","2/3"]]}` + "\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "divide endpoint unhappy path with missing JSON operand",
+			when: "the operand isn't sent in JSON body",
+			then: "error should be returned",
+
+			args:     args{req: missingOperandJSONReq},
+			wantBody: "no such operand in the JSON body: \"b\"\n",
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			resp, err := http.DefaultClient.Do(testCase.args.req)
+			if err != nil {
+				t.Error(err)
+			}
+			if status := resp.StatusCode; status != testCase.wantCode {
+				t.Errorf(unexpectedCode, status, testCase.wantCode)
+			}
+
+			resBody, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Error(err)
+			}
+			if string(resBody) != testCase.wantBody {
+				t.Errorf(unexpectedBody, string(resBody), testCase.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Limits(t *testing.T) {
+	handler := NewHandler(limits{MaxUploadSize: 256, MaxRows: 4, MaxCols: 4, MaxCellLength: 3})
+
+	okReq := SetupCsvRequest(validPath, "/echo", t)
+
+	tooLargeJSONReq := SetupJSONRequest(`{"data":[[`+strings.Repeat("1,", 200)+`1]]}`, "/sum", t)
+
+	// the empty lines are skipped by the CSV reader, so only the upload size limit is hit
+	tooLargeCsvReq := SetupJSONRequest(strings.Repeat("\n", 300)+"1\n", "/echo", t)
+	tooLargeCsvReq.Header.Set("Content-Type", "text/csv")
+
+	tooLargeOperandsReq, writer := SetupOperandsRequest(bigMatrixPath, bigMatrixPath, "/add", t)
+	tooLargeOperandsReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	tooManyColsReq := SetupCsvRequest(bigMatrixPath, "/transpose", t)
+
+	tooManyRowsReq := SetupJSONRequest(`{"data":[[1],[2],[3],[4],[5]]}`, "/sum", t)
+
+	tooLongCellReq := SetupJSONRequest(`{"data":[[1,"12345"]]}`, "/echo", t)
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args     args
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "limits happy path",
+			when: "the matrix is within the limits",
+			then: "the matrix should be processed",
+
+			args:     args{req: okReq},
+			wantCode: http.StatusOK,
+			wantBody: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name: "limits unhappy path with JSON body over the upload size",
+			when: "the JSON body is larger than the max upload size",
+			then: "413 should be returned with the upload size limit in the message",
+
+			args:     args{req: tooLargeJSONReq},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantBody: "request body is too large: more than 256 bytes, the max upload size limit\n",
+		},
+		{
+			name: "limits unhappy path with CSV body over the upload size",
+			when: "the raw CSV body is larger than the max upload size",
+			then: "413 should be returned with the upload size limit in the message",
+
+			args:     args{req: tooLargeCsvReq},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantBody: "request body is too large: more than 256 bytes, the max upload size limit\n",
+		},
+		{
+			name: "limits unhappy path with multipart form over the upload size",
+			when: "the multipart form with both operands is larger than the max upload size",
+			then: "413 should be returned with the upload size limit in the message",
+
+			args:     args{req: tooLargeOperandsReq},
+			wantCode: http.StatusRequestEntityTooLarge,
+			wantBody: "request body is too large: more than 256 bytes, the max upload size limit\n",
+		},
+		{
+			name: "limits unhappy path with too many columns",
+			when: "the matrix has more columns than the limit",
+			then: "422 should be returned with the columns limit in the message",
+
+			args:     args{req: tooManyColsReq},
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "matrix is too large: more than 4 columns, the max columns limit\n",
+		},
+		{
+			name: "limits unhappy path with too many rows",
+			when: "the matrix has more rows than the limit",
+			then: "422 should be returned with the rows limit in the message",
+
+			args:     args{req: tooManyRowsReq},
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "matrix is too large: more than 4 rows, the max rows limit\n",
+		},
+		{
+			name: "limits unhappy path with too long cell",
+			when: "the matrix element is longer than the limit",
+			then: "422 should be returned with the cell length limit and the cell location in the message",
+
+			args:     args{req: tooLongCellReq},
+			wantCode: http.StatusUnprocessableEntity,
+			wantBody: "matrix element is too long, the max cell length limit is 3 bytes: row 1, column 2\n",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, tt.args.req)
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf(errTemplate, meta, recorder.Code, tt.wantCode)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf(errTemplate, meta, got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Storage(t *testing.T) {
+	handler := NewHandler(defaultConfig().limits)
+
+	// only one element is non-zero, but the zeros of the other types aren't integers or floats
+	mostlyZeros := `{"data":[["0.0","0","0","0"],["0","0.00","0","0"],["0","0","0","7"],["0","0","0x0","0"]]}`
+	operands := `{"a":` + mostlyZeros + `,"b":` + mostlyZeros + `}`
+
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		url      string
+		body     string
+		wantCode int
+	}{
+		{
+			name: "storage happy path with transpose",
+			when: "the dense matrix has the zeros written in different ways",
+			then: "the auto storage should keep the text of the elements like the dense one",
+
+			url:      "/transpose?format=csv",
+			body:     mostlyZeros,
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "storage happy path with rational add",
+			when: "the zeros are rational numbers",
+			then: "the auto storage should return the same sum as the dense one",
+
+			url:      "/add?type=rational",
+			body:     operands,
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "storage unhappy path with integer add",
+			when: "the zeros aren't integers",
+			then: "the auto storage should return the same error as the dense one",
+
+			url:      "/add?type=int",
+			body:     operands,
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "storage unhappy path with float matmul",
+			when: "the hexadecimal zero isn't float",
+			then: "the auto storage should return the same error as the dense one",
+
+			url:      "/matmul?type=float",
+			body:     operands,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			dense := httptest.NewRecorder()
+			handler.ServeHTTP(dense, SetupJSONRequest(tt.body, tt.url+"&storage=dense", t))
+			auto := httptest.NewRecorder()
+			handler.ServeHTTP(auto, SetupJSONRequest(tt.body, tt.url+"&storage=auto", t))
+
+			if dense.Code != tt.wantCode {
+				t.Errorf(errTemplate, meta, dense.Code, tt.wantCode)
+			}
+			if auto.Code != dense.Code {
+				t.Errorf(errTemplate, meta, auto.Code, dense.Code)
+			}
+			if got, want := auto.Body.String(), dense.Body.String(); got != want {
+				t.Errorf(errTemplate, meta, got, want)
+			}
+		})
+	}
+}
+
+func TestHandler_UI(t *testing.T) {
+	handler := NewHandler(defaultConfig().limits)
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		path         string
+		wantCode     int
+		wantContains string
+	}{
+		{
+			name: "UI happy path",
+			when: "the root is requested",
+			then: "the page with the operations should be returned",
+
+			path:         "/",
+			wantCode:     http.StatusOK,
+			wantContains: `<option value="matmul" data-binary="true" data-numeric="true">`,
+		},
+		{
+			name: "UI unhappy path with unknown path",
+			when: "the path isn't any route",
+			then: "not found should be returned",
+
+			path:         "/unknown",
+			wantCode:     http.StatusNotFound,
+			wantContains: "404 page not found",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf(errTemplate, meta, recorder.Code, tt.wantCode)
+			}
+			if got := recorder.Body.String(); !strings.Contains(got, tt.wantContains) {
+				t.Errorf(errTemplate, meta, got, tt.wantContains)
+			}
+		})
+	}
+}
+
+func SetupRequest(filePath string, url string, t *testing.T) (*http.Request, *multipart.Writer) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	formFile, err := writer.CreateFormFile(multipartFileKey, filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(filePath)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -489,3 +2055,147 @@ func SetupRequest(filePath string, url string, t *testing.T) (*http.Request, *mu
 
 	return req, writer
 }
+
+func SetupOperandsRequest(aPath string, bPath string, url string, t *testing.T) (*http.Request, *multipart.Writer) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for key, filePath := range map[string]string{multipartLeftOperandKey: aPath, multipartRightOperandKey: bPath} {
+		formFile, err := writer.CreateFormFile(key, filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = io.Copy(formFile, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+	}
+	writer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return req, writer
+}
+
+func SetupJSONRequest(body string, url string, t *testing.T) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func SetupCsvRequest(filePath string, url string, t *testing.T) *http.Request {
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	req, err := http.NewRequest(http.MethodPost, url, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+	return req
+}
+
+func SetupFormFieldRequest(filePath string, url string, t *testing.T) (*http.Request, *multipart.Writer) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	if err := writer.WriteField(multipartFileKey, string(content)); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return req, writer
+}
+
+func Test_getNumericType(t *testing.T) {
+	type args struct {
+		url string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    matrix.Type
+		wantErr error
+	}{
+		{
+			name: "get numeric type happy path",
+			when: "the type isn't specified",
+			then: "the int type should be returned",
+
+			args: args{url: "/sum"},
+			want: matrix.Int,
+		},
+		{
+			name: "get numeric type happy path with float type",
+			when: "the float type is requested",
+			then: "the float type should be returned",
+
+			args: args{url: "/sum?type=float"},
+			want: matrix.Float,
+		},
+		{
+			name: "get numeric type unhappy path",
+			when: "the unknown type is requested",
+			then: "error should be returned",
+
+			args:    args{url: "/sum?type=quaternion"},
+			wantErr: matrix.ErrUnknownType,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getNumericType(httptest.NewRequest("POST", tt.args.url, nil))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+// newHeatmap encodes the matrix as the PNG heatmap, the images are compared byte by byte
+func newHeatmap(t *testing.T, records [][]string, colorMap matrix.ColorMap, labels bool) string {
+	m, err := matrix.NewText(records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	encoder := matrix.NewHeatmapEncoder(&out)
+	encoder.SetColorMap(colorMap)
+	encoder.SetLabels(labels)
+	if err := encoder.Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
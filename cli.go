@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errUnknownCommand      = errors.New("unknown command")
+	errUnknownFormat       = errors.New("unknown format, csv, json, mtx, coo, npy, table, markdown, latex, html and png allowed")
+	errUnknownInputFormat  = errors.New("unknown input format, csv, json, mtx, coo, npy and xlsx allowed")
+	errTooManyCommandFiles = errors.New("only one file allowed")
+	errOperandFiles        = errors.New("two files of the operands a and b required")
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1 // invalid input, or the server failed to start
+	exitUsage   = 2 // unknown command or invalid flags, the same code as the flag package uses
+
+	helpCommand = "help"
+
+	// stdinPath reads the matrix from the standard input, the same as when no file is given
+	stdinPath = "-"
+
+	jsonExtension = ".json"
+)
+
+// unlimited lets the commands read the matrices of any size, the limits only protect the server
+var unlimited = limits{
+	MaxUploadSize: math.MaxInt64,
+	MaxRows:       math.MaxInt,
+	MaxCols:       math.MaxInt,
+	MaxCellLength: math.MaxInt,
+	MaxSparseSize: math.MaxInt,
+	MaxNonZeros:   math.MaxInt,
+}
+
+// runCommand runs the operation with args, the matrix is read from the file given in args or from stdin,
+// the operands of the binary operation are read from the two files given in args.
+// The errors are written to stderr, as plain text or as RFC 7807 problem with the json output format
+func runCommand(name string, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if name == helpCommand {
+		printUsage(stdout)
+		return exitOK
+	}
+	op, ok := lookupOperation(name)
+	if !ok {
+		fmt.Fprintf(stderr, "%s: %q\n\n", errUnknownCommand, name)
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	inputFormat := fs.String("input", "", "input format: csv, json, mtx, coo, npy or xlsx, detected by the file extension when empty")
+	outputFormat := fs.String("format", string(csvFormat), "output format: csv, json, mtx, coo, npy, table, markdown, latex, html or png")
+	indices := fs.Bool(indicesKey, false, "label the rows and columns of table, markdown, latex and html output with their indices")
+	colorMap := fs.String(colorMapKey, string(matrix.Viridis), "color map of png output: viridis, gray, hot or coolwarm")
+	labels := fs.Bool(labelsKey, false, "write the values in the cells of png output, when they fit")
+	var selection xlsxSelection
+	fs.StringVar(&selection.sheet, sheetKey, "", "sheet of the Excel workbook, the first one when empty")
+	fs.StringVar(&selection.cellRange, rangeKey, "", "cell range of the Excel sheet, e.g. A1:C3, all filled cells when empty")
+	typ := string(matrix.Int)
+	if op.Numeric() {
+		fs.StringVar(&typ, "type", typ, "type of the matrix elements: int, int64, bigint, rational, decimal, float or complex")
+	}
+	fs.Usage = func() {
+		if op.Arity() == 2 {
+			fmt.Fprintf(stderr, "Usage: matrix %s [flags] A B\nprint %s, A or B is read from stdin when it's -\n", name, op.Usage())
+		} else {
+			fmt.Fprintf(stderr, "Usage: matrix %s [flags] [FILE]\nprint %s, the matrix is read from stdin when FILE is - or omitted\n", name, op.Usage())
+		}
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	out := &output{format: format(*outputFormat), indices: *indices, colorMap: matrix.ColorMap(*colorMap), labels: *labels}
+	numericType, err := checkCommandArgs(fs.Args(), op.Arity(), *inputFormat, out.format, typ)
+	if err == nil {
+		_, err = matrix.ParseColorMap(*colorMap)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		fs.Usage()
+		return exitUsage
+	}
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{stdinPath}
+	}
+	bufferedOut := bufio.NewWriter(stdout)
+	out.w = bufferedOut
+	err = runOnFiles(paths, format(*inputFormat), selection, stdin, func(opened []matrix.RowReader) error {
+		return runOperation(op, opened, numericType, out)
+	})
+	// the output written before the error is kept, like the output of the streamed responses
+	if flushErr := bufferedOut.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		writeCommandError(stderr, out.format, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// checkCommandArgs validates the positional arguments and the flag values, and resolves the numeric type
+func checkCommandArgs(args []string, arity int, input string, output format, typ string) (matrix.Type, error) {
+	switch {
+	case arity == 2 && len(args) != 2:
+		return "", fmt.Errorf("%w, got %d", errOperandFiles, len(args))
+	case arity == 1 && len(args) > 1:
+		return "", fmt.Errorf("%w, got %d", errTooManyCommandFiles, len(args))
+	}
+	// the Excel workbook is only read, and the human-readable tables and the heatmaps are only written
+	switch format(input) {
+	case "", csvFormat, jsonFormat, mtxFormat, cooFormat, npyFormat, xlsxFormat:
+	default:
+		return "", fmt.Errorf("%w: %q", errUnknownInputFormat, input)
+	}
+	switch output {
+	case "", csvFormat, jsonFormat, mtxFormat, cooFormat, npyFormat, tableFormat, markdownFormat, latexFormat, htmlFormat, pngFormat:
+	default:
+		return "", fmt.Errorf("%w: %q", errUnknownFormat, output)
+	}
+	return matrix.ParseType(typ)
+}
+
+// runOperation reads the operands as the operation requires and writes its result, the matrices aren't limited
+func runOperation(op Operation, opened []matrix.RowReader, typ matrix.Type, out *output) error {
+	args := operationArgs{operands: make([]operand, len(opened))}
+	if op.Numeric() {
+		args.typ = typ
+	}
+	if op.Input() != denseInput {
+		args.storage = autoStorage
+	}
+	for i, rows := range opened {
+		var err error
+		if args.operands[i], err = readOperand(rows, op, args.storage, unlimited); err != nil {
+			return err
+		}
+	}
+	res, err := op.Compute(args)
+	if err != nil {
+		return err
+	}
+	return out.result(res)
+}
+
+// runOnFiles opens the matrices of all files, and passes their rows to run. The files are closed after run returns
+func runOnFiles(paths []string, input format, selection xlsxSelection, stdin io.Reader, run func(opened []matrix.RowReader) error) error {
+	opened := make([]matrix.RowReader, 0, len(paths))
+	var open func(i int) error
+	open = func(i int) error {
+		if i == len(paths) {
+			return run(opened)
+		}
+		return runOnFile(paths[i], input, selection, stdin, func(rows matrix.RowReader) error {
+			opened = append(opened, rows)
+			return open(i + 1)
+		})
+	}
+	return open(0)
+}
+
+// runOnFile opens the matrix from the file or stdin, and passes its rows to run.
+// The JSON, Matrix Market, COO, NumPy and Excel are read into memory at once, the CSV is read row by row
+func runOnFile(path string, input format, selection xlsxSelection, stdin io.Reader, run func(rows matrix.RowReader) error) error {
+	reader := stdin
+	if path != stdinPath {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	if input == "" {
+		switch ext := filepath.Ext(path); {
+		case strings.EqualFold(ext, jsonExtension):
+			input = jsonFormat
+		case strings.EqualFold(ext, mtxExtension):
+			input = mtxFormat
+		case strings.EqualFold(ext, cooExtension):
+			input = cooFormat
+		case strings.EqualFold(ext, npyExtension), strings.EqualFold(ext, npzExtension):
+			input = npyFormat
+		case strings.EqualFold(ext, xlsxExtension):
+			input = xlsxFormat
+		default:
+			input = csvFormat
+		}
+	}
+
+	switch input {
+	case jsonFormat:
+		var body jsonMatrix
+		if err := decodeJSON(reader, &body); err != nil {
+			return err
+		}
+		m, err := body.matrix()
+		if err != nil {
+			return err
+		}
+		return run(m.RowReader())
+	case mtxFormat:
+		rows, err := readMatrixMarket(reader, unlimited)
+		if err != nil {
+			return err
+		}
+		return run(rows)
+	case cooFormat:
+		s, err := readTriplets(reader, unlimited)
+		if err != nil {
+			return err
+		}
+		return run(newSparseRows(s, unlimited))
+	case npyFormat:
+		m, err := readNumPy(reader, unlimited)
+		if err != nil {
+			return err
+		}
+		return run(m.RowReader())
+	case xlsxFormat:
+		m, err := readXlsx(reader, selection, unlimited)
+		if err != nil {
+			return err
+		}
+		return run(m.RowReader())
+	}
+	rows, err := newCsvRowReader(reader, unlimited)
+	if err != nil {
+		return err
+	}
+	return run(rows)
+}
+
+// output writes the results of the commands in the requested format
+type output struct {
+	w       io.Writer
+	format  format
+	indices bool // labels of the human-readable tables
+
+	// color map and value labels of the heatmaps
+	colorMap matrix.ColorMap
+	labels   bool
+}
+
+// matrixRows writes the rows as soon as they're read, the JSON, Matrix Market, COO and NumPy matrices hold the shape
+// before the data, so they're written after all rows are read
+func (o *output) matrixRows(rows matrix.RowReader) error {
+	if o.format != csvFormat {
+		m, err := matrix.ReadAll(rows)
+		if err != nil {
+			return err
+		}
+		return o.matrix(m)
+	}
+	for {
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(o.w, strings.Join(row, ","))
+	}
+}
+
+func (o *output) matrix(m *matrix.Text) error {
+	switch o.format {
+	case jsonFormat:
+		return json.NewEncoder(o.w).Encode(newJSONMatrix(m))
+	case mtxFormat:
+		return writeMatrixMarket(o.w, m)
+	case cooFormat:
+		return o.sparse(m.Sparse())
+	case npyFormat:
+		return writeNumPy(o.w, []int{m.Rows(), m.Cols()}, m.Flatten())
+	case tableFormat, markdownFormat, latexFormat, htmlFormat:
+		encoder := matrix.NewEncoder(o.w, matrix.Style(o.format))
+		encoder.SetIndices(o.indices)
+		return encoder.Encode(m)
+	case pngFormat:
+		encoder := matrix.NewHeatmapEncoder(o.w)
+		encoder.SetColorMap(o.colorMap)
+		encoder.SetLabels(o.labels)
+		return encoder.Encode(m)
+	}
+	_, err := fmt.Fprint(o.w, m.String())
+	return err
+}
+
+// listRows writes the elements of all rows in one line, the Matrix Market and COO list is 1xN matrix,
+// the NumPy one is 1-D array
+func (o *output) listRows(rows matrix.RowReader) error {
+	if o.format != csvFormat {
+		m, err := matrix.ReadAll(rows)
+		if err != nil {
+			return err
+		}
+		elems := m.Flatten()
+		switch o.format {
+		case jsonFormat:
+			return json.NewEncoder(o.w).Encode(jsonResult{Result: newJSONList(elems)})
+		case npyFormat:
+			return writeNumPy(o.w, []int{len(elems)}, elems)
+		}
+		return o.records([][]string{elems})
+	}
+	for i := 0; ; i++ {
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			_, err = fmt.Fprintln(o.w)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprint(o.w, ",")
+		}
+		fmt.Fprint(o.w, strings.Join(row, ","))
+	}
+}
+
+func (o *output) scalar(value string) error {
+	switch o.format {
+	case jsonFormat:
+		return json.NewEncoder(o.w).Encode(jsonResult{Result: newJSONValue(value)})
+	case mtxFormat, cooFormat, tableFormat, markdownFormat, latexFormat, htmlFormat, pngFormat:
+		return o.records([][]string{{value}})
+	case npyFormat:
+		return writeNumPy(o.w, nil, []string{value})
+	}
+	_, err := fmt.Fprintln(o.w, value)
+	return err
+}
+
+// result writes the result of the operation, the matrix and the list rows are written as soon as they're read
+func (o *output) result(res result) error {
+	switch {
+	case res.rows != nil && res.list:
+		return o.listRows(res.rows)
+	case res.rows != nil:
+		return o.matrixRows(res.rows)
+	case res.sparse != nil:
+		return o.sparse(res.sparse)
+	case res.dense != nil:
+		return o.matrix(res.dense)
+	}
+	return o.scalar(res.scalar)
+}
+
+// sparse writes the sparse matrix as is in the COO and Matrix Market formats, and as the dense one otherwise
+func (o *output) sparse(s *matrix.SparseText) error {
+	switch o.format {
+	case cooFormat:
+		_, err := fmt.Fprint(o.w, s.String())
+		return err
+	case mtxFormat:
+		return writeSparseMatrixMarket(o.w, s)
+	}
+	return o.matrix(s.Dense())
+}
+
+func (o *output) records(records [][]string) error {
+	m, err := matrix.NewText(records)
+	if err != nil {
+		return err
+	}
+	return o.matrix(m)
+}
+
+// writeCommandError writes the error as RFC 7807 problem with the json output format, and as plain text otherwise
+func writeCommandError(stderr io.Writer, f format, err error) {
+	if f == jsonFormat {
+		if encodeErr := json.NewEncoder(stderr).Encode(newProblem(err)); encodeErr == nil {
+			return
+		}
+	}
+	fmt.Fprintf(stderr, "matrix: %s\n", err)
+}
+
+// printUsage lists the commands
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(operations))
+	for _, op := range operations {
+		names = append(names, op.Name())
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: matrix <command> [flags] [FILE], or matrix <command> [flags] A B for the binary commands")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintf(w, "  %-12s %s\n", serveCommand, "start the HTTP server, the default when no command is given")
+	for _, name := range names {
+		op, _ := lookupOperation(name)
+		fmt.Fprintf(w, "  %-12s print %s\n", name, op.Usage())
+	}
+	fmt.Fprintln(w, "\nRun matrix <command> -h for the flags of the command")
+}
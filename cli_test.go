@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_runCommand(t *testing.T) {
+	type args struct {
+		name  string
+		args  []string
+		stdin string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args       args
+		wantCode   int
+		wantStdout string
+		wantStderr string // prefix of the expected stderr
+	}{
+		{
+			name: "echo command happy path",
+			when: "the CSV file is given",
+			then: "the matrix should be printed",
+
+			args:       args{name: "echo", args: []string{validPath}},
+			wantCode:   exitOK,
+			wantStdout: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+		{
+			name: "echo command happy path with stdin",
+			when: "the file is omitted",
+			then: "the matrix should be read from stdin",
+
+			args:       args{name: "echo", stdin: "1,2\n3,4\n"},
+			wantCode:   exitOK,
+			wantStdout: "1,2\n3,4\n",
+		},
+		{
+			name: "transpose command happy path",
+			when: "the CSV is given with - as file",
+			then: "the transposed matrix should be printed",
+
+			args:       args{name: "transpose", args: []string{"-"}, stdin: "1,2\n3,4\n"},
+			wantCode:   exitOK,
+			wantStdout: "1,3\n2,4\n",
+		},
+		{
+			name: "transpose command happy path with JSON",
+			when: "the JSON input and output formats are requested",
+			then: "the transposed matrix should be printed as JSON",
+
+			args:       args{name: "transpose", args: []string{"-input", "json", "-format", "json"}, stdin: `{"data":[[1,2],[3,4]]}`},
+			wantCode:   exitOK,
+			wantStdout: `{"rows":2,"cols":2,"data":[[1,3],[2,4]]}` + "\n",
+		},
+		{
+			name: "transpose command happy path with Matrix Market file",
+			when: "the .mtx file is given and the Matrix Market output is requested",
+			then: "the transposed matrix should be printed in the Matrix Market format",
+
+			args:       args{name: "transpose", args: []string{"-format", "mtx", symmetricPath}},
+			wantCode:   exitOK,
+			wantStdout: "%%MatrixMarket matrix array integer general\n3 3\n2\n-1\n0\n-1\n0\n-1\n0\n-1\n2\n",
+		},
+		{
+			name: "transpose command happy path with COO input",
+			when: "the huge sparse matrix is given as COO triplets and the COO output is requested",
+			then: "the transposed triplets should be printed",
+
+			args:       args{name: "transpose", args: []string{"-input", "coo", "-format", "coo"}, stdin: "100000,100000\n1,99999,5\n"},
+			wantCode:   exitOK,
+			wantStdout: "100000,100000\n99999,1,5\n",
+		},
+		{
+			name: "transpose command happy path with table output",
+			when: "the aligned table with indices is requested",
+			then: "the transposed matrix should be printed with the numbers right-aligned",
+
+			args:       args{name: "transpose", args: []string{"-format", "table", "-indices"}, stdin: "1,-20\n300,4\n"},
+			wantCode:   exitOK,
+			wantStdout: "     1    2\n1    1  300\n2  -20    4\n",
+		},
+		{
+			name: "transpose command happy path with PNG output",
+			when: "the heatmap with the hot color map is requested",
+			then: "the transposed matrix should be drawn",
+
+			args:       args{name: "transpose", args: []string{"-format", "png", "-colormap", "hot"}, stdin: "1,2\n3,4\n"},
+			wantCode:   exitOK,
+			wantStdout: newHeatmap(t, [][]string{{"1", "3"}, {"2", "4"}}, matrix.Hot, false),
+		},
+		{
+			name: "transpose command unhappy path with unknown color map",
+			when: "the color map doesn't exist",
+			then: "the exit code should be 2",
+
+			args:       args{name: "transpose", args: []string{"-format", "png", "-colormap", "jet"}, stdin: "1\n"},
+			wantCode:   exitUsage,
+			wantStderr: "unknown color map, viridis, gray, hot and coolwarm allowed: \"jet\"\n",
+		},
+		{
+			name: "flatten command happy path",
+			when: "the CSV file is given",
+			then: "the elements should be printed in one line",
+
+			args:       args{name: "flatten", args: []string{validPath}},
+			wantCode:   exitOK,
+			wantStdout: "1,2,3,4,5,6,7,8,9\n",
+		},
+		{
+			name: "sum command happy path",
+			when: "the CSV file is given",
+			then: "the sum should be printed",
+
+			args:       args{name: "sum", args: []string{validPath}},
+			wantCode:   exitOK,
+			wantStdout: "45\n",
+		},
+		{
+			name: "sum command happy path with float type",
+			when: "the float type and JSON output are requested",
+			then: "the float sum should be printed as JSON",
+
+			args:       args{name: "sum", args: []string{"-type", "float", "-format", "json", floatsPath}},
+			wantCode:   exitOK,
+			wantStdout: `{"result":2003.75}` + "\n",
+		},
+		{
+			name: "sum command happy path with NumPy file",
+			when: "the .npy file is given",
+			then: "the sum of the array should be printed",
+
+			args:       args{name: "sum", args: []string{"-type", "float", fortranNpyPath}},
+			wantCode:   exitOK,
+			wantStdout: "3.75\n",
+		},
+		{
+			name: "sum command happy path with Excel file",
+			when: "the .xlsx file is given with the sheet and the range",
+			then: "the sum of the selected cells should be printed",
+
+			args:       args{name: "sum", args: []string{"-type", "float", "-sheet", "Report", "-range", "B2:B3", xlsxPath}},
+			wantCode:   exitOK,
+			wantStdout: "30.5\n",
+		},
+		{
+			name: "multiply command happy path",
+			when: "the CSV file is given",
+			then: "the product should be printed",
+
+			args:       args{name: "multiply", args: []string{validPath}},
+			wantCode:   exitOK,
+			wantStdout: "362880\n",
+		},
+		{
+			name: "sum command unhappy path with invalid elements",
+			when: "the matrix has non-integer elements",
+			then: "all invalid cells should be reported and the exit code should be 1",
+
+			args:       args{name: "sum", stdin: "1,x\ny,2\n"},
+			wantCode:   exitFailure,
+			wantStderr: "matrix: only integers allowed in matrix: \"x\" at row 1, column 2; \"y\" at row 2, column 1\n",
+		},
+		{
+			name: "sum command unhappy path with JSON output",
+			when: "the matrix has non-integer elements and JSON output is requested",
+			then: "the error should be written as problem",
+
+			args:       args{name: "sum", args: []string{"-format", "json"}, stdin: "1,x\n"},
+			wantCode:   exitFailure,
+			wantStderr: `{"type":"urn:matrix:problem:non_integer_element"`,
+		},
+		{
+			name: "echo command unhappy path with ragged rows",
+			when: "the CSV rows have different length",
+			then: "the rows before the invalid one should be printed and the exit code should be 1",
+
+			args:       args{name: "echo", stdin: "1,2\n3\n"},
+			wantCode:   exitFailure,
+			wantStdout: "1,2\n",
+			wantStderr: "matrix: record on line 2: wrong number of fields\n",
+		},
+		{
+			name: "echo command unhappy path with missing file",
+			when: "the file doesn't exist",
+			then: "the exit code should be 1",
+
+			args:       args{name: "echo", args: []string{"testData/missing.csv"}},
+			wantCode:   exitFailure,
+			wantStderr: "matrix: open testData/missing.csv",
+		},
+		{
+			name: "echo command unhappy path with empty file",
+			when: "the file is empty",
+			then: "the exit code should be 1",
+
+			args:       args{name: "echo", args: []string{emptyPath}},
+			wantCode:   exitFailure,
+			wantStderr: "matrix: matrix shouldn't be empty\n",
+		},
+		{
+			name: "unknown command unhappy path",
+			when: "the command doesn't exist",
+			then: "the usage should be printed and the exit code should be 2",
+
+			args:       args{name: "invert"},
+			wantCode:   exitUsage,
+			wantStderr: "unknown command: \"invert\"\n",
+		},
+		{
+			name: "sum command unhappy path with unknown type",
+			when: "the numeric type doesn't exist",
+			then: "the exit code should be 2",
+
+			args:       args{name: "sum", args: []string{"-type", "quaternion"}},
+			wantCode:   exitUsage,
+			wantStderr: "unknown numeric type, int, int64, bigint, rational, decimal, float and complex allowed: \"quaternion\"\n",
+		},
+		{
+			name: "echo command unhappy path with unknown format",
+			when: "the output format doesn't exist",
+			then: "the exit code should be 2",
+
+			args:       args{name: "echo", args: []string{"-format", "xml"}},
+			wantCode:   exitUsage,
+			wantStderr: "unknown format, csv, json, mtx, coo, npy, table, markdown, latex, html and png allowed: \"xml\"\n",
+		},
+		{
+			name: "echo command unhappy path with output-only input format",
+			when: "the table format is given as the input format",
+			then: "the exit code should be 2",
+
+			args:       args{name: "echo", args: []string{"-input", "table"}},
+			wantCode:   exitUsage,
+			wantStderr: "unknown input format, csv, json, mtx, coo, npy and xlsx allowed: \"table\"\n",
+		},
+		{
+			name: "echo command unhappy path with type flag",
+			when: "the -type flag is passed to the non-numeric command",
+			then: "the exit code should be 2",
+
+			args:       args{name: "echo", args: []string{"-type", "float"}},
+			wantCode:   exitUsage,
+			wantStderr: "flag provided but not defined: -type\n",
+		},
+		{
+			name: "matmul command happy path",
+			when: "the files of both operands are given, the second one is read from stdin",
+			then: "the product should be printed",
+
+			args:       args{name: "matmul", args: []string{rectangular2x3Path, "-"}, stdin: "1,2\n3,4\n5,6\n"},
+			wantCode:   exitOK,
+			wantStdout: "22,28\n49,64\n",
+		},
+		{
+			name: "matmul command unhappy path with one file",
+			when: "only the first operand is given",
+			then: "the exit code should be 2",
+
+			args:       args{name: "matmul", args: []string{rectangular2x3Path}},
+			wantCode:   exitUsage,
+			wantStderr: "two files of the operands a and b required, got 1\n",
+		},
+		{
+			name: "echo command unhappy path with several files",
+			when: "more than one file is given",
+			then: "the exit code should be 2",
+
+			args:       args{name: "echo", args: []string{validPath, validPath}},
+			wantCode:   exitUsage,
+			wantStderr: "only one file allowed, got 2\n",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := runCommand(tt.args.name, tt.args.args, strings.NewReader(tt.args.stdin), &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf(errTemplate, meta, code, tt.wantCode)
+			}
+			if got := stdout.String(); got != tt.wantStdout {
+				t.Errorf(errTemplate, meta, got, tt.wantStdout)
+			}
+			if got := stderr.String(); !strings.HasPrefix(got, tt.wantStderr) || (tt.wantStderr == "") != (got == "") {
+				t.Errorf(errTemplate, meta, got, tt.wantStderr)
+			}
+		})
+	}
+}
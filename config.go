@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	errInvalidConfig = errors.New("invalid config")
+)
+
+const (
+	configFlag = "config"
+	configEnv  = "MATRIX_CONFIG"
+)
+
+// config is the configuration of the server. Every setting is taken from the first source where it's set:
+//  1. command line flags, e.g. -port 9090
+//  2. environment variables, e.g. MATRIX_PORT=9090
+//  3. JSON config file set with -config or MATRIX_CONFIG, e.g. {"port": "9090"}
+//  4. defaults
+type config struct {
+	Host string `json:"host"`
+	Port string `json:"port"`
+
+	ReadTimeout  duration `json:"readTimeout"`
+	WriteTimeout duration `json:"writeTimeout"`
+	IdleTimeout  duration `json:"idleTimeout"`
+
+	// ShutdownTimeout is the grace period for the in-flight requests after SIGINT or SIGTERM
+	ShutdownTimeout duration `json:"shutdownTimeout"`
+
+	limits
+
+	LogLevel string `json:"logLevel"`
+}
+
+// limits bound the size of matrices accepted by the handlers
+type limits struct {
+	MaxUploadSize int64 `json:"maxUploadSize"` // bytes
+	MaxRows       int   `json:"maxRows"`
+	MaxCols       int   `json:"maxCols"`
+	MaxCellLength int   `json:"maxCellLength"` // bytes
+
+	// the sparse matrices aren't bound by MaxRows and MaxCols, as only their non-zero elements take memory
+	MaxSparseSize int `json:"maxSparseSize"` // rows and columns
+	MaxNonZeros   int `json:"maxNonZeros"`
+}
+
+// duration is time.Duration represented in JSON as string, e.g. "30s"
+type duration struct {
+	time.Duration
+}
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
+}
+
+func defaultConfig() config {
+	return config{
+		Port:         defaultPort,
+		ReadTimeout:  duration{5 * time.Minute},
+		WriteTimeout: duration{5 * time.Minute},
+		IdleTimeout:  duration{2 * time.Minute},
+
+		ShutdownTimeout: duration{30 * time.Second},
+		limits: limits{
+			MaxUploadSize: 100 << 20, // 100 MiB
+			MaxRows:       10000,
+			MaxCols:       10000,
+			MaxCellLength: 1024,
+			MaxSparseSize: 1000000,
+			MaxNonZeros:   1000000,
+		},
+		LogLevel: infoLevel.String(),
+	}
+}
+
+// setting is the config field, which can be set with the command line flag or the environment variable
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(cfg *config, value string) error
+}
+
+var settings = []setting{
+	{
+		flag: "host", env: "MATRIX_HOST", usage: "host to listen on, all interfaces when empty",
+		set: func(cfg *config, value string) error { cfg.Host = value; return nil },
+	},
+	{
+		flag: "port", env: "MATRIX_PORT", usage: "port to listen on",
+		set: func(cfg *config, value string) error { cfg.Port = value; return nil },
+	},
+	{
+		flag: "read-timeout", env: "MATRIX_READ_TIMEOUT", usage: "maximum duration for reading the entire request, e.g. 30s",
+		set: func(cfg *config, value string) error { return setDuration(&cfg.ReadTimeout, value) },
+	},
+	{
+		flag: "write-timeout", env: "MATRIX_WRITE_TIMEOUT", usage: "maximum duration before timing out writes of the response",
+		set: func(cfg *config, value string) error { return setDuration(&cfg.WriteTimeout, value) },
+	},
+	{
+		flag: "idle-timeout", env: "MATRIX_IDLE_TIMEOUT", usage: "maximum amount of time to wait for the next request on keep-alive connection",
+		set: func(cfg *config, value string) error { return setDuration(&cfg.IdleTimeout, value) },
+	},
+	{
+		flag: "shutdown-timeout", env: "MATRIX_SHUTDOWN_TIMEOUT", usage: "maximum duration to wait for the in-flight requests on shutdown",
+		set: func(cfg *config, value string) error { return setDuration(&cfg.ShutdownTimeout, value) },
+	},
+	{
+		flag: "max-upload-size", env: "MATRIX_MAX_UPLOAD_SIZE", usage: "maximum size of the request body in bytes",
+		set: func(cfg *config, value string) (err error) {
+			cfg.MaxUploadSize, err = strconv.ParseInt(value, 10, 64)
+			return err
+		},
+	},
+	{
+		flag: "max-rows", env: "MATRIX_MAX_ROWS", usage: "maximum number of rows of matrix",
+		set: func(cfg *config, value string) (err error) {
+			cfg.MaxRows, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		flag: "max-cols", env: "MATRIX_MAX_COLS", usage: "maximum number of columns of matrix",
+		set: func(cfg *config, value string) (err error) {
+			cfg.MaxCols, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		flag: "max-cell-length", env: "MATRIX_MAX_CELL_LENGTH", usage: "maximum length of matrix element in bytes",
+		set: func(cfg *config, value string) (err error) {
+			cfg.MaxCellLength, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		flag: "max-sparse-size", env: "MATRIX_MAX_SPARSE_SIZE", usage: "maximum number of rows and columns of sparse matrix",
+		set: func(cfg *config, value string) (err error) {
+			cfg.MaxSparseSize, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		flag: "max-non-zeros", env: "MATRIX_MAX_NON_ZEROS", usage: "maximum number of non-zero elements of sparse matrix",
+		set: func(cfg *config, value string) (err error) {
+			cfg.MaxNonZeros, err = strconv.Atoi(value)
+			return err
+		},
+	},
+	{
+		flag: "log-level", env: "MATRIX_LOG_LEVEL", usage: "minimum level of the logged messages: debug, info, warn or error",
+		set: func(cfg *config, value string) error { cfg.LogLevel = value; return nil },
+	},
+}
+
+func setDuration(d *duration, value string) (err error) {
+	d.Duration, err = time.ParseDuration(value)
+	return err
+}
+
+// loadConfig builds the effective config from the command line arguments, the environment and the config file
+func loadConfig(args []string, getenv func(string) string) (config, error) {
+	cfg := defaultConfig()
+
+	// the flags are parsed first to find the config file, but applied last, as they have the highest priority
+	fs := flag.NewFlagSet("matrix", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String(configFlag, "", "path to JSON config file, also set with "+configEnv)
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		name := s.flag
+		fs.Func(name, fmt.Sprintf("%s (env %s)", s.usage, s.env), func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, fmt.Errorf("%w: %s", errInvalidConfig, err.Error())
+	}
+
+	if *configPath == "" {
+		*configPath = getenv(configEnv)
+	}
+	if *configPath != "" {
+		if err := readConfigFile(*configPath, &cfg); err != nil {
+			return cfg, err
+		}
+	}
+
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.set(&cfg, value); err != nil {
+				return cfg, fmt.Errorf("%w: environment variable %s=%q: %s", errInvalidConfig, s.env, value, err.Error())
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flagValues[s.flag]; ok {
+			if err := s.set(&cfg, value); err != nil {
+				return cfg, fmt.Errorf("%w: flag -%s=%q: %s", errInvalidConfig, s.flag, value, err.Error())
+			}
+		}
+	}
+
+	return cfg, cfg.validate()
+}
+
+func readConfigFile(path string, cfg *config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidConfig, err.Error())
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("%w: config file %s: %s", errInvalidConfig, path, err.Error())
+	}
+	return nil
+}
+
+// validate checks that the config values are usable by the server
+func (cfg config) validate() error {
+	if port, err := strconv.Atoi(cfg.Port); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%w: port should be a number between 1 and 65535, got %q", errInvalidConfig, cfg.Port)
+	}
+	for _, timeout := range []struct {
+		name  string
+		value duration
+	}{
+		{name: "read timeout", value: cfg.ReadTimeout},
+		{name: "write timeout", value: cfg.WriteTimeout},
+		{name: "idle timeout", value: cfg.IdleTimeout},
+		{name: "shutdown timeout", value: cfg.ShutdownTimeout},
+	} {
+		if timeout.value.Duration < 0 {
+			return fmt.Errorf("%w: %s shouldn't be negative, got %s", errInvalidConfig, timeout.name, timeout.value)
+		}
+	}
+	if cfg.MaxUploadSize <= 0 {
+		return fmt.Errorf("%w: max upload size should be positive, got %d", errInvalidConfig, cfg.MaxUploadSize)
+	}
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{name: "max rows", value: cfg.MaxRows},
+		{name: "max columns", value: cfg.MaxCols},
+		{name: "max cell length", value: cfg.MaxCellLength},
+		{name: "max sparse size", value: cfg.MaxSparseSize},
+		{name: "max non-zeros", value: cfg.MaxNonZeros},
+	} {
+		if limit.value <= 0 {
+			return fmt.Errorf("%w: %s should be positive, got %d", errInvalidConfig, limit.name, limit.value)
+		}
+	}
+	if _, err := parseLogLevel(cfg.LogLevel); err != nil {
+		return fmt.Errorf("%w: %s", errInvalidConfig, err.Error())
+	}
+	return nil
+}
+
+// String represents the config as JSON, for logging
+func (cfg config) String() string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
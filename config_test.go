@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_loadConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(configPath, []byte(`{"port": "9000", "readTimeout": "10s", "maxRows": 50}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invalidConfigPath := filepath.Join(t.TempDir(), "invalid.json")
+	err = os.WriteFile(invalidConfigPath, []byte(`{"prot": "9000"}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type args struct {
+		args []string
+		env  map[string]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    func(cfg *config) // modification of the default config
+		wantErr error
+	}{
+		{
+			name: "load config happy path",
+			when: "nothing is set",
+			then: "default config should be returned",
+
+			want: func(cfg *config) {},
+		},
+		{
+			name: "load config happy path with config file",
+			when: "the config file is set with flag",
+			then: "the values from file should override defaults",
+
+			args: args{args: []string{"-config", configPath}},
+			want: func(cfg *config) {
+				cfg.Port = "9000"
+				cfg.ReadTimeout = duration{10 * time.Second}
+				cfg.MaxRows = 50
+			},
+		},
+		{
+			name: "load config happy path with env",
+			when: "the config file and env variables are set",
+			then: "the env variables should override the config file",
+
+			args: args{env: map[string]string{
+				"MATRIX_CONFIG":       configPath,
+				"MATRIX_PORT":         "9001",
+				"MATRIX_IDLE_TIMEOUT": "1m",
+				"MATRIX_LOG_LEVEL":    "debug",
+			}},
+			want: func(cfg *config) {
+				cfg.Port = "9001"
+				cfg.ReadTimeout = duration{10 * time.Second}
+				cfg.IdleTimeout = duration{time.Minute}
+				cfg.MaxRows = 50
+				cfg.LogLevel = "debug"
+			},
+		},
+		{
+			name: "load config happy path with flags",
+			when: "the flags, env variables and the config file are set",
+			then: "the flags should override everything",
+
+			args: args{
+				args: []string{"-config", configPath, "-port", "9002", "-max-upload-size", "1024"},
+				env:  map[string]string{"MATRIX_PORT": "9001", "MATRIX_HOST": "127.0.0.1"},
+			},
+			want: func(cfg *config) {
+				cfg.Host = "127.0.0.1"
+				cfg.Port = "9002"
+				cfg.ReadTimeout = duration{10 * time.Second}
+				cfg.MaxUploadSize = 1024
+				cfg.MaxRows = 50
+			},
+		},
+		{
+			name: "load config unhappy path with invalid env",
+			when: "the env variable can't be parsed",
+			then: "error should be returned",
+
+			args:    args{env: map[string]string{"MATRIX_WRITE_TIMEOUT": "forever"}},
+			wantErr: errInvalidConfig,
+		},
+		{
+			name: "load config unhappy path with unknown field in config file",
+			when: "the config file has a typo",
+			then: "error should be returned",
+
+			args:    args{args: []string{"-config", invalidConfigPath}},
+			wantErr: errInvalidConfig,
+		},
+		{
+			name: "load config unhappy path with unknown flag",
+			when: "the unknown flag is passed",
+			then: "error should be returned",
+
+			args:    args{args: []string{"-prot", "9000"}},
+			wantErr: errInvalidConfig,
+		},
+		{
+			name: "load config unhappy path with invalid port",
+			when: "the port is out of range",
+			then: "error should be returned",
+
+			args:    args{args: []string{"-port", "70000"}},
+			wantErr: errInvalidConfig,
+		},
+		{
+			name: "load config unhappy path with invalid log level",
+			when: "the log level is unknown",
+			then: "error should be returned",
+
+			args:    args{args: []string{"-log-level", "verbose"}},
+			wantErr: errInvalidConfig,
+		},
+		{
+			name: "load config unhappy path with not positive limit",
+			when: "the max cell length is zero",
+			then: "error should be returned",
+
+			args:    args{args: []string{"-max-cell-length", "0"}},
+			wantErr: errInvalidConfig,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.args.env[key] }
+			got, err := loadConfig(tt.args.args, getenv)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := defaultConfig()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf(errTemplate, meta, got, want)
+			}
+		})
+	}
+}
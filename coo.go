@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errInvalidTriplets = errors.New("invalid COO triplets")
+)
+
+const (
+	// COO (coordinate list) is the CSV of the sparse matrix: the first line is "rows,cols",
+	// and each next one is "row,column,value" of the non-zero element with 1-based row and column
+	cooExtension   = ".coo"
+	cooContentType = "text/x-coo"
+)
+
+// readTriplets reads the sparse matrix in the COO format. The declared shape and the number of elements are checked
+// against the sparse limits while the matrix is read, the elements not listed are zeros
+func readTriplets(reader io.Reader, l limits) (*matrix.SparseText, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	shape, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, matrix.ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(shape) != 2 {
+		return nil, tripletsErrorf(csvReader, "the first line should be rows,cols, got %d fields", len(shape))
+	}
+	rows, errRows := strconv.Atoi(shape[0])
+	cols, errCols := strconv.Atoi(shape[1])
+	if errRows != nil || errCols != nil || rows < 0 || cols < 0 {
+		return nil, tripletsErrorf(csvReader, "%q isn't valid shape", shape[0]+","+shape[1])
+	}
+	if err := checkSparseLimits(rows, cols, 0, l); err != nil {
+		return nil, err
+	}
+
+	var entries []matrix.Entry[string]
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != 3 {
+			return nil, tripletsErrorf(csvReader, "the element should be row,column,value, got %d fields", len(record))
+		}
+		i, errRow := strconv.Atoi(record[0])
+		j, errCol := strconv.Atoi(record[1])
+		if errRow != nil || errCol != nil {
+			return nil, tripletsErrorf(csvReader, "%q isn't valid position", record[0]+","+record[1])
+		}
+		if err := checkSparseLimits(rows, cols, len(entries)+1, l); err != nil {
+			return nil, err
+		}
+		entries = append(entries, matrix.Entry[string]{Row: i - 1, Col: j - 1, Value: record[2]})
+	}
+	s, err := matrix.NewSparseText(rows, cols, entries)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSparseMatrixLimits(s, l); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func tripletsErrorf(reader *csv.Reader, format string, args ...any) error {
+	line, _ := reader.FieldPos(0)
+	return fmt.Errorf("%w: line %d: %s", errInvalidTriplets, line, fmt.Sprintf(format, args...))
+}
+
+// checkSparseMatrixLimits checks the sparse matrix against the limits, including the length of the non-zero elements
+func checkSparseMatrixLimits(s *matrix.SparseText, l limits) error {
+	if err := checkSparseLimits(s.Rows(), s.Cols(), s.NonZeros(), l); err != nil {
+		return err
+	}
+	for _, e := range s.Entries() {
+		if len(e.Value) > l.MaxCellLength {
+			return newCellTooLongError(e.Row+1, e.Col+1, l)
+		}
+	}
+	return nil
+}
+
+// checkSparseLimits checks the shape and the number of non-zero elements of the sparse matrix against the limits
+func checkSparseLimits(rows, cols, nonZeros int, l limits) error {
+	if rows > l.MaxSparseSize || cols > l.MaxSparseSize {
+		return fmt.Errorf("%w: more than %d rows or columns, the max sparse size limit", errMatrixTooLarge, l.MaxSparseSize)
+	}
+	if nonZeros > l.MaxNonZeros {
+		return fmt.Errorf("%w: more than %d non-zero elements, the max non-zeros limit", errMatrixTooLarge, l.MaxNonZeros)
+	}
+	return nil
+}
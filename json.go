@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errInvalidJSONBody    = errors.New("invalid JSON body")
+	errInvalidJSONElement = errors.New("matrix elements should be numbers or strings")
+	errMissingJSONOperand = errors.New("no such operand in the JSON body")
+)
+
+const (
+	jsonContentType = "application/json"
+)
+
+// jsonNumberRegexp matches the number literals allowed by the JSON grammar
+var jsonNumberRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// jsonMatrix is the JSON representation of matrix, used for both requests and responses.
+// The rows and cols fields are ignored in requests, the shape is taken from data
+type jsonMatrix struct {
+	Rows int     `json:"rows"`
+	Cols int     `json:"cols"`
+	Data [][]any `json:"data"`
+}
+
+// jsonResult is the JSON representation of the scalar and flat results
+type jsonResult struct {
+	Result any `json:"result"`
+}
+
+// jsonOperands is the JSON request body of the binary operations
+type jsonOperands struct {
+	A *jsonMatrix `json:"a"`
+	B *jsonMatrix `json:"b"`
+}
+
+// newJSONMatrix converts matrix to its JSON representation, the numeric elements are encoded as JSON numbers
+func newJSONMatrix(m *matrix.Text) jsonMatrix {
+	res := jsonMatrix{Rows: m.Rows(), Cols: m.Cols(), Data: make([][]any, m.Rows())}
+	for i := range res.Data {
+		res.Data[i] = newJSONList(m.Row(i))
+	}
+	return res
+}
+
+// newJSONList converts elements to JSON values, the numeric elements are encoded as JSON numbers
+func newJSONList(elems []string) []any {
+	res := make([]any, len(elems))
+	for i := range elems {
+		res[i] = newJSONValue(elems[i])
+	}
+	return res
+}
+
+// newJSONValue encodes the element as JSON number when possible, e.g. "45" is encoded as 45,
+// but "3/4" and "NaN" stay strings
+func newJSONValue(elem string) any {
+	if jsonNumberRegexp.MatchString(elem) {
+		return json.Number(elem)
+	}
+	return elem
+}
+
+// matrix converts the JSON representation of matrix to the matrix, the shape is checked by matrix.New
+func (m jsonMatrix) matrix() (*matrix.Text, error) {
+	records := make([][]string, len(m.Data))
+	var errs matrix.CellErrors
+	for i := range m.Data {
+		records[i] = make([]string, len(m.Data[i]))
+		for j, elem := range m.Data[i] {
+			switch v := elem.(type) {
+			case json.Number:
+				records[i][j] = v.String()
+			case string:
+				records[i][j] = v
+			default:
+				errs.Add(&matrix.CellError{Err: errInvalidJSONElement, Row: i + 1, Col: j + 1, Value: fmt.Sprint(elem)})
+			}
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return matrix.NewText(records)
+}
+
+func readJSONMatrix(w http.ResponseWriter, r *http.Request) (*matrix.Text, error) {
+	var body jsonMatrix
+	if err := decodeJSONBody(r, &body); err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	m, err := body.matrix()
+	if err == nil {
+		err = checkMatrixLimits(m, getLimitsFromCtx(r.Context()))
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	return m, nil
+}
+
+func readJSONOperands(w http.ResponseWriter, r *http.Request) (a, b *matrix.Text, err error) {
+	var ops jsonOperands
+	if err = decodeJSONBody(r, &ops); err != nil {
+		writeError(w, r, err)
+		return nil, nil, err
+	}
+	if ops.A == nil || ops.B == nil {
+		key := multipartLeftOperandKey
+		if ops.A != nil {
+			key = multipartRightOperandKey
+		}
+		err = fmt.Errorf("%w: %q", errMissingJSONOperand, key)
+		writeError(w, r, err)
+		return nil, nil, err
+	}
+
+	l := getLimitsFromCtx(r.Context())
+	if a, err = ops.A.matrix(); err == nil {
+		err = checkMatrixLimits(a, l)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return nil, nil, err
+	}
+	if b, err = ops.B.matrix(); err == nil {
+		err = checkMatrixLimits(b, l)
+	}
+	if err != nil {
+		writeError(w, r, err)
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+func decodeJSONBody(r *http.Request, v any) error {
+	return decodeJSON(r.Body, v)
+}
+
+func decodeJSON(reader io.Reader, v any) error {
+	decoder := json.NewDecoder(reader)
+	// keep the numbers as they were sent, so the big ones don't lose precision in float64
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return err
+		}
+		return fmt.Errorf("%w: %s", errInvalidJSONBody, err.Error())
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", jsonContentType)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		// the status is already sent, so it's only possible to log the error
+		logError(err)
+	}
+}
+
+// isJSONRequest checks if the request body is sent as JSON
+func isJSONRequest(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Content-Type"), jsonContentType)
+}
+
+// acceptsJSON checks if the client asks for the JSON response
+func acceptsJSON(r *http.Request) bool {
+	return hasMediaType(r.Header.Get("Accept"), jsonContentType)
+}
+
+// hasMediaType checks if the comma separated list of media types from header consists mediaType,
+// the parameters like charset or q are ignored
+func hasMediaType(header string, mediaType string) bool {
+	for _, value := range strings.Split(header, ",") {
+		parsed, _, err := mime.ParseMediaType(strings.TrimSpace(value))
+		if err == nil && parsed == mediaType {
+			return true
+		}
+	}
+	return false
+}
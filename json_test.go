@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_newJSONValue(t *testing.T) {
+	type args struct {
+		elem string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want any
+	}{
+		{
+			name: "new JSON value happy path with integer",
+			when: "the element is integer",
+			then: "JSON number should be returned",
+
+			args: args{elem: "-45"},
+			want: json.Number("-45"),
+		},
+		{
+			name: "new JSON value happy path with float",
+			when: "the element is float in exponent notation",
+			then: "JSON number should be returned",
+
+			args: args{elem: "2.5e-3"},
+			want: json.Number("2.5e-3"),
+		},
+		{
+			name: "new JSON value happy path with fraction",
+			when: "the element isn't valid JSON number",
+			then: "string should be returned",
+
+			args: args{elem: "3/4"},
+			want: "3/4",
+		},
+		{
+			name: "new JSON value happy path with leading plus",
+			when: "the element is number, which isn't allowed by JSON grammar",
+			then: "string should be returned",
+
+			args: args{elem: "+5"},
+			want: "+5",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newJSONValue(tt.args.elem); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_jsonMatrix_matrix(t *testing.T) {
+	type args struct {
+		matrix jsonMatrix
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "JSON matrix records happy path",
+			when: "the data consists numbers and numeric strings",
+			then: "string matrix should be returned",
+
+			args: args{matrix: jsonMatrix{Data: [][]any{{json.Number("1"), "2"}, {json.Number("3.5"), "4"}}}},
+			want: [][]string{{"1", "2"}, {"3.5", "4"}},
+		},
+		{
+			name: "JSON matrix records unhappy path with empty data",
+			when: "the data is empty",
+			then: "error should be returned",
+
+			args:    args{matrix: jsonMatrix{Data: [][]any{}}},
+			wantErr: matrix.ErrEmpty,
+		},
+		{
+			name: "JSON matrix records unhappy path with ragged rows",
+			when: "the rows have different length",
+			then: "error should be returned",
+
+			args:    args{matrix: jsonMatrix{Data: [][]any{{json.Number("1"), json.Number("2")}, {json.Number("3")}}}},
+			wantErr: matrix.ErrRagged,
+		},
+		{
+			name: "JSON matrix records unhappy path with invalid element",
+			when: "the data consists boolean",
+			then: "error should be returned",
+
+			args:    args{matrix: jsonMatrix{Data: [][]any{{true}}}},
+			wantErr: errInvalidJSONElement,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.args.matrix.matrix()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hasMediaType(t *testing.T) {
+	type args struct {
+		header    string
+		mediaType string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want bool
+	}{
+		{
+			name: "has media type happy path",
+			when: "the header consists the media type with parameters",
+			then: "true should be returned",
+
+			args: args{header: "text/html, application/json; q=0.9", mediaType: jsonContentType},
+			want: true,
+		},
+		{
+			name: "has media type happy path with missing media type",
+			when: "the header doesn't consist the media type",
+			then: "false should be returned",
+
+			args: args{header: "*/*", mediaType: jsonContentType},
+			want: false,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasMediaType(tt.args.header, tt.args.mediaType); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// logLevel is the severity of the logged message, the messages below the configured level are dropped
+type logLevel int
+
+const (
+	debugLevel logLevel = iota
+	infoLevel
+	warnLevel
+	errorLevel
+)
+
+var logLevelNames = map[logLevel]string{
+	debugLevel: "debug",
+	infoLevel:  "info",
+	warnLevel:  "warn",
+	errorLevel: "error",
+}
+
+// currentLogLevel is set once at startup from the config
+var currentLogLevel = infoLevel
+
+func (l logLevel) String() string {
+	return logLevelNames[l]
+}
+
+func parseLogLevel(s string) (logLevel, error) {
+	for level, name := range logLevelNames {
+		if strings.EqualFold(s, name) {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, debug, info, warn and error allowed", s)
+}
+
+func logAt(level logLevel, v ...any) {
+	if level < currentLogLevel {
+		return
+	}
+	log.Println(append([]any{strings.ToUpper(level.String())}, v...)...)
+}
+
+func logDebug(v ...any) { logAt(debugLevel, v...) }
+func logInfo(v ...any)  { logAt(infoLevel, v...) }
+func logWarn(v ...any)  { logAt(warnLevel, v...) }
+func logError(v ...any) { logAt(errorLevel, v...) }
@@ -1,34 +1,91 @@
 package main
 
 import (
-	"fmt"
-	"log"
-	"net"
-	"net/http"
+	"os"
+	"strings"
 )
 
 const (
 	multipartFileKey = "file"
 
-	csvExtension = ".csv"
+	// multipart keys of the operands for the binary operations, e.g. a·b
+	multipartLeftOperandKey  = "a"
+	multipartRightOperandKey = "b"
 
-	// FUTURE CONSIDERATION: read host and port from env variables
+	csvExtension   = ".csv"
+	csvContentType = "text/csv"
+
+	// query parameter of the response format, overrides the Accept header
+	formatKey = "format"
+
+	// query parameter labeling the rows and columns of the human-readable tables with their indices
+	indicesKey = "indices"
+
+	// query parameters of the color map and the value labels of the PNG heatmaps, see matrix.HeatmapEncoder
+	colorMapKey = "colormap"
+	labelsKey   = "labels"
+
+	// query parameter of the matrix storage, also used as the context key, see sparse.go
+	storageKey = "storage"
+
+	// query parameters of the sheet and the cell range of the Excel workbook, see xlsx.go
+	sheetKey = "sheet"
+	rangeKey = "range"
+
+	// default port, see config.go for the other ways to set it
 	defaultPort = "8080"
 
-	recordsKey = "records"
+	// query parameter of the matrix elements type, also used as the context key of the numeric operations
+	numericTypeKey = "type"
+
+	operandsKey = "operands"
+	limitsKey   = "limits"
+)
+
+// format is the format of the matrix input and output
+type format string
+
+const (
+	csvFormat  format = "csv"
+	jsonFormat format = "json"
+	mtxFormat  format = "mtx" // Matrix Market, see mtx.go
+	cooFormat  format = "coo" // triplets of the sparse matrix, see coo.go
+	npyFormat  format = "npy" // NumPy array, see npy.go
+
+	// xlsxFormat is only read, see xlsx.go
+	xlsxFormat format = "xlsx"
+
+	// the human-readable tables are only written, see matrix.Encoder
+	tableFormat    format = "table"
+	markdownFormat format = "markdown"
+	latexFormat    format = "latex"
+	htmlFormat     format = "html"
+
+	// pngFormat is the heatmap image, it's only written
+	pngFormat format = "png"
 )
 
 // Run with
 //		go run .
 // Send request with:
 //		curl -F 'file=@./testData/matrix.csv' "localhost:8080/echo"
+// Or open localhost:8080 in the browser, see ui.go
+// Or run the operation on the local file without the server:
+//		go run . sum ./testData/matrix.csv
+// See config.go for the available flags and environment variables, and operations.go for the operations
 
 func main() {
-	handler := NewHandler()
-	log.Println("server is running on port " + defaultPort)
-	err := http.ListenAndServe(net.JoinHostPort("", defaultPort), handler)
-	if err != nil {
-		log.Println(fmt.Sprintf("error during http listening: %s", err.Error()))
-		return
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches the command and returns the exit code. The server is started when no command is given,
+// so the flags can still be passed without the serve command, e.g. go run . -port 9090
+func run(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runServer(args)
+	}
+	if args[0] == serveCommand {
+		return runServer(args[1:])
 	}
+	return runCommand(args[0], args[1:], os.Stdin, os.Stdout, os.Stderr)
 }
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errInvalidMatrixMarket    = errors.New("invalid Matrix Market file")
+	errNonNumericMatrixMarket = errors.New("only numeric matrices can be written in Matrix Market format")
+)
+
+const (
+	mtxExtension   = ".mtx"
+	mtxContentType = "text/x-matrix-market"
+
+	// mtxBanner starts the header line of the Matrix Market file
+	mtxBanner = "%%MatrixMarket"
+)
+
+// Matrix Market formats, fields and symmetries, see https://math.nist.gov/MatrixMarket/formats.html
+const (
+	mtxCoordinate = "coordinate" // the non-zero entries only, "row column value"
+	mtxArray      = "array"      // all entries in column-major order
+
+	mtxInteger = "integer"
+	mtxReal    = "real"
+	mtxComplex = "complex" // the real and imaginary parts separated by space
+	mtxPattern = "pattern" // the positions of the non-zero entries without values, coordinate format only
+
+	mtxGeneral       = "general"
+	mtxSymmetric     = "symmetric"      // only the lower triangle is stored, a[j][i] = a[i][j]
+	mtxSkewSymmetric = "skew-symmetric" // only the strictly lower triangle is stored, a[j][i] = -a[i][j]
+	mtxHermitian     = "hermitian"      // only the lower triangle is stored, a[j][i] is the conjugate of a[i][j]
+)
+
+// mtxHeader is the type of the matrix declared in the banner line
+type mtxHeader struct {
+	format, field, symmetry string
+}
+
+// mtxEntry is the element of the Matrix Market file, the imaginary part is empty for the non-complex fields
+type mtxEntry struct {
+	re, im string
+}
+
+// mtxReader reads the Matrix Market file line by line, the line number is reported in the errors
+type mtxReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// readMatrixMarket reads the matrix in the coordinate or array format. The declared size is checked against
+// the limits before the matrix is read. The coordinate matrix is kept sparse, its omitted entries are zeros
+func readMatrixMarket(reader io.Reader, l limits) (matrix.RowReader, error) {
+	mtx := &mtxReader{reader: bufio.NewReader(reader)}
+	header, err := mtx.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	sizeLen := 2
+	if header.format == mtxCoordinate {
+		sizeLen = 3
+	}
+	size, err := mtx.readSize(sizeLen)
+	if err != nil {
+		return nil, err
+	}
+	rows, cols := size[0], size[1]
+	if rows == 0 || cols == 0 {
+		return nil, matrix.ErrEmpty
+	}
+	if header.symmetry != mtxGeneral && rows != cols {
+		return nil, mtx.errorf("%s matrix should be square, got %dx%d", header.symmetry, rows, cols)
+	}
+
+	var entries []matrix.Entry[string]
+	set := func(i, j int, e mtxEntry) {
+		entries = append(entries, matrix.Entry[string]{Row: i, Col: j, Value: e.String()})
+		if i == j || header.symmetry == mtxGeneral {
+			return
+		}
+		mirrored := e
+		switch header.symmetry {
+		case mtxSkewSymmetric:
+			mirrored = e.negate()
+		case mtxHermitian:
+			mirrored = e.conjugate()
+		}
+		entries = append(entries, matrix.Entry[string]{Row: j, Col: i, Value: mirrored.String()})
+	}
+
+	if header.format == mtxArray {
+		if err := checkShapeLimits(rows, cols, l); err != nil {
+			return nil, err
+		}
+		if err := mtx.readArray(header, rows, cols, set); err != nil {
+			return nil, err
+		}
+		s, err := matrix.NewSparseText(rows, cols, entries)
+		if err != nil {
+			return nil, err
+		}
+		m := s.Dense()
+		if err := checkMatrixLimits(m, l); err != nil {
+			return nil, err
+		}
+		return m.RowReader(), nil
+	}
+
+	if err := checkSparseLimits(rows, cols, size[2], l); err != nil {
+		return nil, err
+	}
+	if err := mtx.readCoordinates(header, rows, cols, size[2], set); err != nil {
+		return nil, err
+	}
+	s, err := matrix.NewSparseText(rows, cols, entries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidMatrixMarket, err.Error())
+	}
+	if err := checkSparseMatrixLimits(s, l); err != nil {
+		return nil, err
+	}
+	return newSparseRows(s, l), nil
+}
+
+// readHeader reads the banner line, e.g. %%MatrixMarket matrix coordinate real general
+func (mtx *mtxReader) readHeader() (mtxHeader, error) {
+	fields, err := mtx.readFields()
+	if errors.Is(err, io.EOF) {
+		return mtxHeader{}, matrix.ErrEmpty
+	}
+	if err != nil {
+		return mtxHeader{}, err
+	}
+	if len(fields) != 5 || fields[0] != mtxBanner || !strings.EqualFold(fields[1], "matrix") {
+		return mtxHeader{}, mtx.errorf("the first line should be %s matrix <format> <field> <symmetry>", mtxBanner)
+	}
+
+	header := mtxHeader{format: strings.ToLower(fields[2]), field: strings.ToLower(fields[3]), symmetry: strings.ToLower(fields[4])}
+	switch {
+	case header.format != mtxCoordinate && header.format != mtxArray:
+		return mtxHeader{}, mtx.errorf("unknown format %q, %s and %s allowed", fields[2], mtxCoordinate, mtxArray)
+	case header.field != mtxInteger && header.field != mtxReal && header.field != mtxComplex && header.field != mtxPattern:
+		return mtxHeader{}, mtx.errorf("unknown field %q, %s, %s, %s and %s allowed", fields[3], mtxInteger, mtxReal, mtxComplex, mtxPattern)
+	case header.symmetry != mtxGeneral && header.symmetry != mtxSymmetric && header.symmetry != mtxSkewSymmetric && header.symmetry != mtxHermitian:
+		return mtxHeader{}, mtx.errorf("unknown symmetry %q, %s, %s, %s and %s allowed", fields[4], mtxGeneral, mtxSymmetric, mtxSkewSymmetric, mtxHermitian)
+	case header.field == mtxPattern && header.format != mtxCoordinate:
+		return mtxHeader{}, mtx.errorf("%s field is allowed in %s format only", mtxPattern, mtxCoordinate)
+	case header.field == mtxPattern && header.symmetry == mtxSkewSymmetric:
+		return mtxHeader{}, mtx.errorf("%s field can't be %s", mtxPattern, mtxSkewSymmetric)
+	case header.symmetry == mtxHermitian && header.field != mtxComplex:
+		return mtxHeader{}, mtx.errorf("%s symmetry is allowed for %s field only", mtxHermitian, mtxComplex)
+	}
+	return header, nil
+}
+
+// readSize reads the size line after the comments: "rows columns" for array, "rows columns entries" for coordinate
+func (mtx *mtxReader) readSize(n int) ([]int, error) {
+	fields, err := mtx.readDataFields()
+	if errors.Is(err, io.EOF) {
+		return nil, mtx.errorf("the size line is missing")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != n {
+		return nil, mtx.errorf("the size line should have %d numbers, got %d", n, len(fields))
+	}
+	size := make([]int, n)
+	for i := range fields {
+		if size[i], err = strconv.Atoi(fields[i]); err != nil || size[i] < 0 {
+			return nil, mtx.errorf("%q isn't valid size", fields[i])
+		}
+	}
+	return size, nil
+}
+
+// readCoordinates reads the declared number of "row column value" entries, the positions are 1-based.
+// The entries of the symmetric matrices should be in the lower triangle
+func (mtx *mtxReader) readCoordinates(header mtxHeader, rows, cols, count int, set func(i, j int, e mtxEntry)) error {
+	for k := 0; k < count; k++ {
+		fields, err := mtx.readDataFields()
+		if errors.Is(err, io.EOF) {
+			return mtx.errorf("%d entries declared, but %d found", count, k)
+		}
+		if err != nil {
+			return err
+		}
+		if len(fields) < 2 {
+			return mtx.errorf("the entry should start with the row and column")
+		}
+		i, errRow := strconv.Atoi(fields[0])
+		j, errCol := strconv.Atoi(fields[1])
+		if errRow != nil || errCol != nil || i < 1 || i > rows || j < 1 || j > cols {
+			return mtx.errorf("the position %s %s is out of %dx%d matrix", fields[0], fields[1], rows, cols)
+		}
+		if header.symmetry == mtxSkewSymmetric && i <= j || header.symmetry != mtxGeneral && i < j {
+			return mtx.errorf("the entry %d %d of %s matrix should be in the lower triangle", i, j, header.symmetry)
+		}
+		e, err := mtx.parseEntry(header.field, fields[2:])
+		if err != nil {
+			return err
+		}
+		set(i-1, j-1, e)
+	}
+	return mtx.checkEnd(count)
+}
+
+// readArray reads the entries in column-major order, only the lower triangle of the symmetric matrices is stored
+func (mtx *mtxReader) readArray(header mtxHeader, rows, cols int, set func(i, j int, e mtxEntry)) error {
+	var count int
+	for j := 0; j < cols; j++ {
+		first := 0
+		switch header.symmetry {
+		case mtxSymmetric, mtxHermitian:
+			first = j
+		case mtxSkewSymmetric:
+			first = j + 1
+		}
+		for i := first; i < rows; i++ {
+			fields, err := mtx.readDataFields()
+			if errors.Is(err, io.EOF) {
+				return mtx.errorf("the entry at row %d, column %d is missing", i+1, j+1)
+			}
+			if err != nil {
+				return err
+			}
+			e, err := mtx.parseEntry(header.field, fields)
+			if err != nil {
+				return err
+			}
+			set(i, j, e)
+			count++
+		}
+	}
+	return mtx.checkEnd(count)
+}
+
+// parseEntry checks the value of the entry, the pattern entries have no value and are ones
+func (mtx *mtxReader) parseEntry(field string, fields []string) (mtxEntry, error) {
+	want := map[string]int{mtxInteger: 1, mtxReal: 1, mtxComplex: 2, mtxPattern: 0}[field]
+	if len(fields) != want {
+		return mtxEntry{}, mtx.errorf("the %s entry should have %d values, got %d", field, want, len(fields))
+	}
+	if field == mtxPattern {
+		return mtxEntry{re: "1"}, nil
+	}
+	for _, value := range fields {
+		valid := false
+		if field == mtxInteger {
+			_, valid = new(big.Int).SetString(value, 10)
+		} else {
+			_, valid = new(big.Float).SetString(value)
+		}
+		if !valid {
+			return mtxEntry{}, mtx.errorf("%q isn't %s number", value, field)
+		}
+	}
+	e := mtxEntry{re: fields[0]}
+	if field == mtxComplex {
+		e.im = fields[1]
+	}
+	return e, nil
+}
+
+// checkEnd checks that there are no entries after the declared ones
+func (mtx *mtxReader) checkEnd(count int) error {
+	_, err := mtx.readDataFields()
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return mtx.errorf("more than %d entries found", count)
+}
+
+// readDataFields reads the fields of the next line, skipping the comments and the empty lines
+func (mtx *mtxReader) readDataFields() ([]string, error) {
+	for {
+		fields, err := mtx.readFields()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 && !strings.HasPrefix(fields[0], "%") {
+			return fields, nil
+		}
+	}
+}
+
+// readFields reads the whitespace separated fields of the next line, io.EOF is returned after the last line
+func (mtx *mtxReader) readFields() ([]string, error) {
+	line, err := mtx.reader.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		// the last line without the line break
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	mtx.line++
+	return strings.Fields(line), nil
+}
+
+func (mtx *mtxReader) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", errInvalidMatrixMarket, mtx.line, fmt.Sprintf(format, args...))
+}
+
+// String writes the entry the way the numeric types parse it, e.g. 1.5 or 1-2i
+func (e mtxEntry) String() string {
+	if e.im == "" {
+		return e.re
+	}
+	if strings.HasPrefix(e.im, "-") || strings.HasPrefix(e.im, "+") {
+		return e.re + e.im + "i"
+	}
+	return e.re + "+" + e.im + "i"
+}
+
+func (e mtxEntry) negate() mtxEntry {
+	e.re = negateNumber(e.re)
+	if e.im != "" {
+		e.im = negateNumber(e.im)
+	}
+	return e
+}
+
+func (e mtxEntry) conjugate() mtxEntry {
+	e.im = negateNumber(e.im)
+	return e
+}
+
+// negateNumber flips the sign of the valid number without parsing it, so its precision is kept
+func negateNumber(s string) string {
+	switch {
+	case strings.HasPrefix(s, "-"):
+		return s[1:]
+	case strings.HasPrefix(s, "+"):
+		return "-" + s[1:]
+	}
+	return "-" + s
+}
+
+// writeMatrixMarket writes the numeric matrix in the coordinate format, when at most half of the entries
+// are non-zero, and in the array format otherwise. The field is the narrowest one of integer, real and complex
+func writeMatrixMarket(w io.Writer, m *matrix.Text) error {
+	if s := m.Sparse(); s.NonZeros() <= m.Rows()*m.Cols()/2 {
+		return writeSparseMatrixMarket(w, s)
+	}
+	field, entries, err := newMtxEntries(m.Flatten())
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "%s matrix %s %s %s\n", mtxBanner, mtxArray, field, mtxGeneral)
+	fmt.Fprintf(out, "%d %d\n", m.Rows(), m.Cols())
+	for j := 0; j < m.Cols(); j++ {
+		for i := 0; i < m.Rows(); i++ {
+			fmt.Fprintln(out, entries[i*m.Cols()+j].fields())
+		}
+	}
+	return out.Flush()
+}
+
+// writeSparseMatrixMarket writes the stored elements of the sparse matrix in the coordinate format
+func writeSparseMatrixMarket(w io.Writer, s *matrix.SparseText) error {
+	stored := s.Entries()
+	elems := make([]string, len(stored))
+	for k, e := range stored {
+		elems[k] = e.Value
+	}
+	field, entries, err := newMtxEntries(elems)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "%s matrix %s %s %s\n", mtxBanner, mtxCoordinate, field, mtxGeneral)
+	fmt.Fprintf(out, "%d %d %d\n", s.Rows(), s.Cols(), len(entries))
+	for k, e := range entries {
+		fmt.Fprintf(out, "%d %d %s\n", stored[k].Row+1, stored[k].Col+1, e.fields())
+	}
+	return out.Flush()
+}
+
+// newMtxEntries converts the elements to the entries of the narrowest field.
+// The fractions like 3/4 have no Matrix Market representation, so they're written as real numbers
+func newMtxEntries(elems []string) (string, []mtxEntry, error) {
+	entries := make([]mtxEntry, len(elems))
+
+	integer := true
+	for _, elem := range elems {
+		if _, ok := new(big.Int).SetString(elem, 10); !ok {
+			integer = false
+			break
+		}
+	}
+	if integer {
+		for k, elem := range elems {
+			entries[k] = mtxEntry{re: elem}
+		}
+		return mtxInteger, entries, nil
+	}
+
+	isReal := true
+	for k, elem := range elems {
+		if _, err := strconv.ParseFloat(elem, 64); err == nil {
+			entries[k] = mtxEntry{re: elem}
+			continue
+		}
+		x, ok := new(big.Rat).SetString(elem)
+		if !ok {
+			isReal = false
+			break
+		}
+		f, _ := x.Float64()
+		entries[k] = mtxEntry{re: strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	if isReal {
+		return mtxReal, entries, nil
+	}
+
+	for k, elem := range elems {
+		x, err := strconv.ParseComplex(elem, 128)
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: %q isn't a number", errNonNumericMatrixMarket, elem)
+		}
+		entries[k] = mtxEntry{re: strconv.FormatFloat(real(x), 'g', -1, 64), im: strconv.FormatFloat(imag(x), 'g', -1, 64)}
+	}
+	return mtxComplex, entries, nil
+}
+
+// fields writes the entry as the Matrix Market values, the complex parts are separated by space
+func (e mtxEntry) fields() string {
+	if e.im == "" {
+		return e.re
+	}
+	return e.re + " " + e.im
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_readMatrixMarket(t *testing.T) {
+	type args struct {
+		mtx    string
+		limits limits
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "read Matrix Market happy path with general coordinate matrix",
+			when: "the non-zero entries are listed with comments between them",
+			then: "the omitted entries should be zeros",
+
+			args: args{mtx: "%%MatrixMarket matrix coordinate real general\n% comment\n2 3 2\n1 2 1.5\n\n2 3 -4\n"},
+			want: [][]string{{"0", "1.5", "0"}, {"0", "0", "-4"}},
+		},
+		{
+			name: "read Matrix Market happy path with symmetric coordinate matrix",
+			when: "only the lower triangle is listed",
+			then: "the upper triangle should be mirrored",
+
+			args: args{mtx: "%%MatrixMarket matrix coordinate integer symmetric\n2 2 2\n1 1 5\n2 1 7\n"},
+			want: [][]string{{"5", "7"}, {"7", "0"}},
+		},
+		{
+			name: "read Matrix Market happy path with skew-symmetric array matrix",
+			when: "only the strictly lower triangle is listed column by column",
+			then: "the upper triangle should be negated and the diagonal should be zeros",
+
+			args: args{mtx: "%%MatrixMarket matrix array real skew-symmetric\n3 3\n1.5\n-2\n3\n"},
+			want: [][]string{{"0", "-1.5", "2"}, {"1.5", "0", "-3"}, {"-2", "3", "0"}},
+		},
+		{
+			name: "read Matrix Market happy path with hermitian complex matrix",
+			when: "only the lower triangle of complex matrix is listed",
+			then: "the upper triangle should be conjugated",
+
+			args: args{mtx: "%%MatrixMarket matrix coordinate complex hermitian\n2 2 3\n1 1 1 0\n2 1 2 -3\n2 2 4 0\n"},
+			want: [][]string{{"1+0i", "2+3i"}, {"2-3i", "4+0i"}},
+		},
+		{
+			name: "read Matrix Market happy path with general array matrix",
+			when: "all entries are listed column by column",
+			then: "the matrix should be returned row by row",
+
+			args: args{mtx: "%%MatrixMarket matrix array integer general\n2 2\n1\n3\n2\n4"},
+			want: [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name: "read Matrix Market happy path with pattern matrix",
+			when: "only the positions of the entries are listed",
+			then: "the entries should be ones",
+
+			args: args{mtx: "%%MatrixMarket matrix coordinate pattern general\n2 2 2\n1 1\n2 2\n"},
+			want: [][]string{{"1", "0"}, {"0", "1"}},
+		},
+		{
+			name: "read Matrix Market unhappy path with empty file",
+			when: "the file is empty",
+			then: "error should be returned",
+
+			args:    args{mtx: ""},
+			wantErr: matrix.ErrEmpty,
+		},
+		{
+			name: "read Matrix Market unhappy path with invalid banner",
+			when: "the first line isn't the Matrix Market banner",
+			then: "error should be returned",
+
+			args:    args{mtx: "1,2\n3,4\n"},
+			wantErr: errInvalidMatrixMarket,
+		},
+		{
+			name: "read Matrix Market unhappy path with upper triangle entry",
+			when: "the entry of symmetric matrix is above the diagonal",
+			then: "error should be returned",
+
+			args:    args{mtx: "%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 3\n"},
+			wantErr: errInvalidMatrixMarket,
+		},
+		{
+			name: "read Matrix Market unhappy path with missing entries",
+			when: "less entries than declared are listed",
+			then: "error should be returned",
+
+			args:    args{mtx: "%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 3\n"},
+			wantErr: errInvalidMatrixMarket,
+		},
+		{
+			name: "read Matrix Market unhappy path with invalid number",
+			when: "the integer entry is float",
+			then: "error should be returned",
+
+			args:    args{mtx: "%%MatrixMarket matrix array integer general\n1 1\n1.5\n"},
+			wantErr: errInvalidMatrixMarket,
+		},
+		{
+			name: "read Matrix Market unhappy path with too large matrix",
+			when: "the declared size of the array matrix is over the limits",
+			then: "error should be returned before the entries are read",
+
+			args:    args{mtx: "%%MatrixMarket matrix array real general\n100000 100000\n", limits: defaultConfig().limits},
+			wantErr: errMatrixTooLarge,
+		},
+		{
+			name: "read Matrix Market unhappy path with too large sparse matrix",
+			when: "the declared number of entries of the coordinate matrix is over the limits",
+			then: "error should be returned before the entries are read",
+
+			args:    args{mtx: "%%MatrixMarket matrix coordinate real general\n100000 100000 2000000\n", limits: defaultConfig().limits},
+			wantErr: errMatrixTooLarge,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.args.limits
+			if l == (limits{}) {
+				l = unlimited
+			}
+			got, err := readMatrixMarket(strings.NewReader(tt.args.mtx), l)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := matrix.ReadAll(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m.Records(), tt.want) {
+				t.Errorf(errTemplate, meta, m.Records(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeMatrixMarket(t *testing.T) {
+	type args struct {
+		records [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "write Matrix Market happy path with dense integer matrix",
+			when: "most of the elements are non-zero integers",
+			then: "the integer array should be written column by column",
+
+			args: args{records: [][]string{{"1", "2"}, {"3", "0"}}},
+			want: "%%MatrixMarket matrix array integer general\n2 2\n1\n3\n2\n0\n",
+		},
+		{
+			name: "write Matrix Market happy path with sparse real matrix",
+			when: "at most half of the elements are non-zero and one of them is fraction",
+			then: "the real coordinates of the non-zero elements should be written",
+
+			args: args{records: [][]string{{"0", "1/4"}, {"0", "0"}}},
+			want: "%%MatrixMarket matrix coordinate real general\n2 2 1\n1 2 0.25\n",
+		},
+		{
+			name: "write Matrix Market happy path with complex matrix",
+			when: "one of the elements is complex",
+			then: "the real and imaginary parts should be written",
+
+			args: args{records: [][]string{{"1+2i", "3"}}},
+			want: "%%MatrixMarket matrix array complex general\n1 2\n1 2\n3 0\n",
+		},
+		{
+			name: "write Matrix Market unhappy path with text",
+			when: "one of the elements isn't a number",
+			then: "error should be returned",
+
+			args:    args{records: [][]string{{"1", "x"}}},
+			wantErr: errNonNumericMatrixMarket,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := matrix.NewText(tt.args.records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			err = writeMatrixMarket(&got, m)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf(errTemplate, meta, got.String(), tt.want)
+			}
+		})
+	}
+}
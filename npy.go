@@ -0,0 +1,321 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errInvalidNpy = errors.New("invalid NumPy array")
+	errNonRealNpy = errors.New("only integer and real matrices can be written as NumPy array")
+	errBigIntNpy  = errors.New("integers beyond int64 can't be written as NumPy array, use csv or json format")
+)
+
+const (
+	// NumPy .npy is the binary array with the header holding its dtype, order and shape, .npz is the zip archive of .npy files,
+	// see https://numpy.org/doc/stable/reference/generated/numpy.lib.format.html
+	npyExtension   = ".npy"
+	npzExtension   = ".npz"
+	npyContentType = "application/x-npy"
+
+	npyMagic = "\x93NUMPY"
+	zipMagic = "PK\x03\x04"
+
+	// npyAlignment is the alignment of the array data, the header is padded with spaces up to it
+	npyAlignment = 64
+	// npyMaxHeaderLength bounds the header length read from the versions 2.0 and 3.0, which take up to 4 GiB.
+	// The real headers are shorter than 1 KiB
+	npyMaxHeaderLength = 1 << 16
+)
+
+var (
+	npyDescrPattern = regexp.MustCompile(`['"]descr['"]\s*:\s*['"]([^'"]*)['"]`)
+	npyOrderPattern = regexp.MustCompile(`['"]fortran_order['"]\s*:\s*(True|False)`)
+	npyShapePattern = regexp.MustCompile(`['"]shape['"]\s*:\s*\(([^)]*)\)`)
+)
+
+// npyHeader is the parsed header of .npy, e.g. {'descr': '<i8', 'fortran_order': False, 'shape': (2, 3), }
+type npyHeader struct {
+	descr        string
+	fortranOrder bool
+	shape        []int
+}
+
+// readNumPy reads the matrix from .npy, or from .npz holding exactly one array. The 1-D array is read as one row,
+// the 0-D one as 1x1 matrix. The shape is checked against the limits before the data is read
+func readNumPy(reader io.Reader, l limits) (*matrix.Text, error) {
+	buffered := bufio.NewReader(reader)
+	magic, err := buffered.Peek(len(zipMagic))
+	if len(magic) == 0 && errors.Is(err, io.EOF) {
+		return nil, matrix.ErrEmpty
+	}
+	if string(magic) == zipMagic {
+		return readNpz(buffered, l)
+	}
+	return readNpy(buffered, l)
+}
+
+// readNpz reads the archive into memory, as zip keeps its directory at the end
+func readNpz(reader io.Reader, l limits) (*matrix.Text, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidNpy, err.Error())
+	}
+	var arrays []*zip.File
+	for _, f := range archive.File {
+		if strings.HasSuffix(f.Name, npyExtension) {
+			arrays = append(arrays, f)
+		}
+	}
+	if len(arrays) != 1 {
+		return nil, fmt.Errorf("%w: .npz should hold one array, got %d", errInvalidNpy, len(arrays))
+	}
+	file, err := arrays[0].Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidNpy, err.Error())
+	}
+	defer file.Close()
+	return readNpy(file, l)
+}
+
+func readNpy(reader io.Reader, l limits) (*matrix.Text, error) {
+	header, err := readNpyHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	size, decode, err := npyDecoder(header.descr)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cols := 1, 1
+	switch len(header.shape) {
+	case 0:
+	case 1:
+		cols = header.shape[0]
+	case 2:
+		rows, cols = header.shape[0], header.shape[1]
+	default:
+		return nil, fmt.Errorf("%w: only 1-D and 2-D arrays allowed, got %d dimensions", errInvalidNpy, len(header.shape))
+	}
+	if rows == 0 || cols == 0 {
+		return nil, matrix.ErrEmpty
+	}
+	if err := checkShapeLimits(rows, cols, l); err != nil {
+		return nil, err
+	}
+	if rows > math.MaxInt/cols/size {
+		return nil, fmt.Errorf("%w: %dx%d array doesn't fit into memory", errMatrixTooLarge, rows, cols)
+	}
+	length := int64(rows * cols * size)
+	if length > l.MaxUploadSize {
+		return nil, fmt.Errorf("%w: %dx%d array takes %d bytes, more than %d bytes, the max upload size limit",
+			errBodyTooLarge, rows, cols, length, l.MaxUploadSize)
+	}
+
+	// the data is read as it arrives, so the shape of the short file doesn't allocate the whole array
+	data, err := io.ReadAll(io.LimitReader(reader, length))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != length {
+		return nil, fmt.Errorf("%w: %d bytes of data expected, got %d", errInvalidNpy, length, len(data))
+	}
+	records := make([][]string, rows)
+	for i := range records {
+		records[i] = make([]string, cols)
+	}
+	for k := 0; k < rows*cols; k++ {
+		i, j := k/cols, k%cols
+		if header.fortranOrder {
+			i, j = k%rows, k/rows
+		}
+		records[i][j] = decode(data[k*size : (k+1)*size])
+	}
+	return matrix.NewText(records)
+}
+
+// readNpyHeader reads the magic string, the version and the header. The header length takes 2 bytes in the version 1.0
+// and 4 bytes in the later ones
+func readNpyHeader(reader io.Reader) (npyHeader, error) {
+	prefix := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(reader, prefix); err != nil || string(prefix[:len(npyMagic)]) != npyMagic {
+		return npyHeader{}, fmt.Errorf("%w: the file should start with %q", errInvalidNpy, npyMagic)
+	}
+	var length int
+	switch major := prefix[len(npyMagic)]; major {
+	case 1:
+		var n uint16
+		if err := binary.Read(reader, binary.LittleEndian, &n); err != nil {
+			return npyHeader{}, fmt.Errorf("%w: %s", errInvalidNpy, err.Error())
+		}
+		length = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(reader, binary.LittleEndian, &n); err != nil {
+			return npyHeader{}, fmt.Errorf("%w: %s", errInvalidNpy, err.Error())
+		}
+		length = int(n)
+	default:
+		return npyHeader{}, fmt.Errorf("%w: version %d isn't supported", errInvalidNpy, major)
+	}
+	if length > npyMaxHeaderLength {
+		return npyHeader{}, fmt.Errorf("%w: the header takes %d bytes, at most %d allowed", errInvalidNpy, length, npyMaxHeaderLength)
+	}
+	text := make([]byte, length)
+	if _, err := io.ReadFull(reader, text); err != nil {
+		return npyHeader{}, fmt.Errorf("%w: %s", errInvalidNpy, err.Error())
+	}
+	return parseNpyHeader(string(text))
+}
+
+// parseNpyHeader parses the Python dict literal of the header
+func parseNpyHeader(text string) (npyHeader, error) {
+	descr := npyDescrPattern.FindStringSubmatch(text)
+	order := npyOrderPattern.FindStringSubmatch(text)
+	shape := npyShapePattern.FindStringSubmatch(text)
+	if descr == nil || order == nil || shape == nil {
+		return npyHeader{}, fmt.Errorf("%w: the header should have descr, fortran_order and shape, got %q", errInvalidNpy, strings.TrimSpace(text))
+	}
+
+	header := npyHeader{descr: descr[1], fortranOrder: order[1] == "True"}
+	for _, dim := range strings.Split(shape[1], ",") {
+		if dim = strings.TrimSpace(dim); dim == "" {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(dim, "L"))
+		if err != nil || n < 0 {
+			return npyHeader{}, fmt.Errorf("%w: %q isn't valid shape", errInvalidNpy, shape[1])
+		}
+		header.shape = append(header.shape, n)
+	}
+	return header, nil
+}
+
+// npyDecoder returns the size of the element of the dtype, e.g. <i8 or >f4, and the decoder of its value.
+// Only the signed and unsigned integers and the 32-bit and 64-bit floats are supported
+func npyDecoder(descr string) (int, func([]byte) string, error) {
+	unsupported := fmt.Errorf("%w: dtype %q isn't supported, only integers and floats allowed", errInvalidNpy, descr)
+	if len(descr) < 3 {
+		return 0, nil, unsupported
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	switch descr[0] {
+	case '<', '=', '|':
+	case '>':
+		order = binary.BigEndian
+	default:
+		return 0, nil, unsupported
+	}
+	size, err := strconv.Atoi(descr[2:])
+	if err != nil {
+		return 0, nil, unsupported
+	}
+
+	switch kind := descr[1]; {
+	case kind == 'i' && size == 1:
+		return size, func(b []byte) string { return strconv.Itoa(int(int8(b[0]))) }, nil
+	case kind == 'i' && size == 2:
+		return size, func(b []byte) string { return strconv.Itoa(int(int16(order.Uint16(b)))) }, nil
+	case kind == 'i' && size == 4:
+		return size, func(b []byte) string { return strconv.Itoa(int(int32(order.Uint32(b)))) }, nil
+	case kind == 'i' && size == 8:
+		return size, func(b []byte) string { return strconv.FormatInt(int64(order.Uint64(b)), 10) }, nil
+	case kind == 'u' && size == 1:
+		return size, func(b []byte) string { return strconv.Itoa(int(b[0])) }, nil
+	case kind == 'u' && size == 2:
+		return size, func(b []byte) string { return strconv.Itoa(int(order.Uint16(b))) }, nil
+	case kind == 'u' && size == 4:
+		return size, func(b []byte) string { return strconv.FormatUint(uint64(order.Uint32(b)), 10) }, nil
+	case kind == 'u' && size == 8:
+		return size, func(b []byte) string { return strconv.FormatUint(order.Uint64(b), 10) }, nil
+	case kind == 'f' && size == 4:
+		return size, func(b []byte) string {
+			return strconv.FormatFloat(float64(math.Float32frombits(order.Uint32(b))), 'g', -1, 32)
+		}, nil
+	case kind == 'f' && size == 8:
+		return size, func(b []byte) string { return strconv.FormatFloat(math.Float64frombits(order.Uint64(b)), 'g', -1, 64) }, nil
+	}
+	return 0, nil, unsupported
+}
+
+// writeNumPy writes the elements as .npy of the given shape in C order. The dtype is <i8 when all elements
+// are 64-bit integers, and <f8 otherwise, the fractions are rounded to the nearest float. The integers beyond int64
+// are rejected rather than rounded
+func writeNumPy(w io.Writer, shape []int, elems []string) error {
+	descr, data, err := encodeNpyData(elems)
+	if err != nil {
+		return err
+	}
+
+	dims := make([]string, len(shape))
+	for k, n := range shape {
+		dims[k] = strconv.Itoa(n)
+	}
+	tuple := "(" + strings.Join(dims, ", ") + ")"
+	if len(shape) == 1 {
+		tuple = "(" + dims[0] + ",)"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': %s, }", descr, tuple)
+	// the magic string, the version, the header length and the trailing newline precede the aligned data
+	prefixLength := len(npyMagic) + 2 + 2
+	padding := npyAlignment - (prefixLength+len(header)+1)%npyAlignment
+	header += strings.Repeat(" ", padding%npyAlignment) + "\n"
+
+	out := bufio.NewWriter(w)
+	out.WriteString(npyMagic)
+	out.Write([]byte{1, 0})
+	binary.Write(out, binary.LittleEndian, uint16(len(header)))
+	out.WriteString(header)
+	out.Write(data)
+	return out.Flush()
+}
+
+func encodeNpyData(elems []string) (string, []byte, error) {
+	data := make([]byte, 8*len(elems))
+
+	integer := true
+	for k, elem := range elems {
+		x, err := strconv.ParseInt(elem, 10, 64)
+		if errors.Is(err, strconv.ErrRange) {
+			return "", nil, fmt.Errorf("%w: %s", errBigIntNpy, elem)
+		}
+		if err != nil {
+			integer = false
+			break
+		}
+		binary.LittleEndian.PutUint64(data[8*k:], uint64(x))
+	}
+	if integer {
+		return "<i8", data, nil
+	}
+
+	for k, elem := range elems {
+		x, err := strconv.ParseFloat(elem, 64)
+		if err != nil {
+			r, ok := new(big.Rat).SetString(elem)
+			if !ok {
+				return "", nil, fmt.Errorf("%w: %q isn't a real number", errNonRealNpy, elem)
+			}
+			x, _ = r.Float64()
+		}
+		binary.LittleEndian.PutUint64(data[8*k:], math.Float64bits(x))
+	}
+	return "<f8", data, nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_readNumPy(t *testing.T) {
+	type args struct {
+		data   []byte
+		limits limits
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "read NumPy happy path with int64 array",
+			when: "the 2-D array is stored in C order",
+			then: "the matrix should be returned row by row",
+
+			args: args{data: newNpy("<i8", false, "(2, 2)", []int64{1, -2, 3, 4})},
+			want: [][]string{{"1", "-2"}, {"3", "4"}},
+		},
+		{
+			name: "read NumPy happy path with Fortran order",
+			when: "the big-endian float32 array is stored column by column",
+			then: "the matrix should be returned row by row",
+
+			args: args{data: newNpy(">f4", true, "(2, 3)", []float32{1, 4, 2.5, 5, 3, -6})},
+			want: [][]string{{"1", "2.5", "3"}, {"4", "5", "-6"}},
+		},
+		{
+			name: "read NumPy happy path with 1-D array",
+			when: "the vector of unsigned bytes is sent",
+			then: "the matrix with one row should be returned",
+
+			args: args{data: newNpy("|u1", false, "(3,)", []uint8{0, 7, 255})},
+			want: [][]string{{"0", "7", "255"}},
+		},
+		{
+			name: "read NumPy happy path with .npz archive",
+			when: "the archive holds one array",
+			then: "the array should be read",
+
+			args: args{data: readTestFile(t, "testData/matrix.npz")},
+			want: [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name: "read NumPy unhappy path with empty file",
+			when: "the file is empty",
+			then: "error should be returned",
+
+			args:    args{data: nil},
+			wantErr: matrix.ErrEmpty,
+		},
+		{
+			name: "read NumPy unhappy path with wrong magic",
+			when: "the file isn't NumPy array",
+			then: "error should be returned",
+
+			args:    args{data: []byte("1,2\n3,4\n")},
+			wantErr: errInvalidNpy,
+		},
+		{
+			name: "read NumPy unhappy path with unsupported dtype",
+			when: "the array of strings is sent",
+			then: "error should be returned",
+
+			args:    args{data: newNpy("<U1", false, "(1, 1)", []uint32{'x'})},
+			wantErr: errInvalidNpy,
+		},
+		{
+			name: "read NumPy unhappy path with 3-D array",
+			when: "the array has three dimensions",
+			then: "error should be returned",
+
+			args:    args{data: newNpy("<i8", false, "(1, 1, 1)", []int64{1})},
+			wantErr: errInvalidNpy,
+		},
+		{
+			name: "read NumPy unhappy path with truncated data",
+			when: "the data is shorter than the shape",
+			then: "error should be returned",
+
+			args:    args{data: newNpy("<i8", false, "(2, 2)", []int64{1, 2, 3})},
+			wantErr: errInvalidNpy,
+		},
+		{
+			name: "read NumPy unhappy path with too large array",
+			when: "the shape exceeds the limits",
+			then: "error should be returned before the data is read",
+
+			args:    args{data: newNpy("<f8", false, "(100000, 100000)", []float64{}), limits: defaultConfig().limits},
+			wantErr: errMatrixTooLarge,
+		},
+		{
+			name: "read NumPy unhappy path with too large data",
+			when: "the shape is within the rows and columns limits, but its data exceeds the max upload size",
+			then: "error should be returned before the data is allocated",
+
+			args:    args{data: newNpy("<f8", false, "(10000, 10000)", []float64{}), limits: defaultConfig().limits},
+			wantErr: errBodyTooLarge,
+		},
+		{
+			name: "read NumPy unhappy path with too long header",
+			when: "the version 2.0 header length is 4 GiB",
+			then: "error should be returned before the header is allocated",
+
+			args:    args{data: []byte(npyMagic + "\x02\x00\xff\xff\xff\xff{'descr': '<i8'")},
+			wantErr: errInvalidNpy,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.args.limits
+			if l == (limits{}) {
+				l = unlimited
+			}
+			got, err := readNumPy(bytes.NewReader(tt.args.data), l)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Records(), tt.want) {
+				t.Errorf(errTemplate, meta, got.Records(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_writeNumPy(t *testing.T) {
+	type args struct {
+		shape []int
+		elems []string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args      args
+		wantDescr string
+		wantShape string
+		wantData  any
+		wantErr   error
+	}{
+		{
+			name: "write NumPy happy path with integer matrix",
+			when: "all elements are 64-bit integers",
+			then: "the int64 array should be written in C order",
+
+			args:      args{shape: []int{2, 2}, elems: []string{"1", "2", "3", "-4"}},
+			wantDescr: "<i8",
+			wantShape: "(2, 2)",
+			wantData:  []int64{1, 2, 3, -4},
+		},
+		{
+			name: "write NumPy happy path with real list",
+			when: "one of the elements is fraction",
+			then: "the float64 vector should be written",
+
+			args:      args{shape: []int{3}, elems: []string{"1", "0.5", "1/4"}},
+			wantDescr: "<f8",
+			wantShape: "(3,)",
+			wantData:  []float64{1, 0.5, 0.25},
+		},
+		{
+			name: "write NumPy happy path with scalar",
+			when: "the shape is empty",
+			then: "the 0-D array should be written",
+
+			args:      args{shape: nil, elems: []string{"45"}},
+			wantDescr: "<i8",
+			wantShape: "()",
+			wantData:  []int64{45},
+		},
+		{
+			name: "write NumPy unhappy path with complex matrix",
+			when: "one of the elements is complex",
+			then: "error should be returned",
+
+			args:    args{shape: []int{1, 2}, elems: []string{"1", "2+3i"}},
+			wantErr: errNonRealNpy,
+		},
+		{
+			name: "write NumPy unhappy path with big integers",
+			when: "one of the elements doesn't fit into int64",
+			then: "error should be returned instead of the rounded float64 matrix",
+
+			args:    args{shape: []int{1, 2}, elems: []string{"1", "9223372036854775808"}},
+			wantErr: errBigIntNpy,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			var got bytes.Buffer
+			err := writeNumPy(&got, tt.args.shape, tt.args.elems)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := newNpy(tt.wantDescr, false, tt.wantShape, tt.wantData)
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf(errTemplate, meta, got.Bytes(), want)
+			}
+		})
+	}
+}
+
+// newNpy builds .npy of version 1.0 with the data aligned to 64 bytes, as numpy.save does
+func newNpy(descr string, fortranOrder bool, shape string, data any) []byte {
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': %s, }", descr, pythonBool(fortranOrder), shape)
+	header += strings.Repeat(" ", (npyAlignment-(10+len(header)+1)%npyAlignment)%npyAlignment) + "\n"
+
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	buf.Write([]byte{1, 0})
+	binary.Write(&buf, binary.LittleEndian, uint16(len(header)))
+	buf.WriteString(header)
+	var order binary.ByteOrder = binary.LittleEndian
+	if descr[0] == '>' {
+		order = binary.BigEndian
+	}
+	binary.Write(&buf, order, data)
+	return buf.Bytes()
+}
+
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+func readTestFile(t *testing.T, path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
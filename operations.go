@@ -0,0 +1,169 @@
+package main
+
+import (
+	"matrix/pkg/matrix"
+)
+
+// operations are served as the routes and run as the commands, in the order of the /operations listing.
+// Adding the operation here is enough to get its route, command, listing and the entry in the browser UI
+var operations = []Operation{
+	operation{
+		name:  "echo",
+		usage: "the matrix as is",
+		arity: 1,
+		input: rowsInput,
+		compute: func(args operationArgs) (result, error) {
+			return result{rows: args.operands[0].rows}, nil
+		},
+	},
+	operation{
+		name:  "transpose",
+		usage: "the matrix with rows and columns swapped",
+		arity: 1,
+		input: storedInput,
+		compute: func(args operationArgs) (result, error) {
+			m := args.operands[0]
+			if m.sparse != nil {
+				return result{sparse: m.sparse.Transpose()}, nil
+			}
+			return result{dense: m.dense.Transpose()}, nil
+		},
+	},
+	operation{
+		name:    "inverse",
+		usage:   "the inverse of the square matrix",
+		arity:   1,
+		shape:   squareShape,
+		numeric: true,
+		input:   denseInput,
+		compute: func(args operationArgs) (result, error) {
+			return denseResult(args.operands[0].dense.Inverse(args.typ))
+		},
+	},
+	operation{
+		name:  "flatten",
+		usage: "the matrix elements in one line",
+		arity: 1,
+		input: rowsInput,
+		compute: func(args operationArgs) (result, error) {
+			return result{rows: args.operands[0].rows, list: true}, nil
+		},
+	},
+	operation{
+		name:    "sum",
+		usage:   "the sum of the matrix elements",
+		arity:   1,
+		numeric: true,
+		input:   rowsInput,
+		compute: func(args operationArgs) (result, error) {
+			rows := args.operands[0].rows
+			if s := sparseOf(rows, args.storage); s != nil {
+				return scalarResult(s.Sum(args.typ))
+			}
+			return scalarResult(matrix.SumRows(args.typ, rows))
+		},
+	},
+	operation{
+		name:    "multiply",
+		usage:   "the product of the matrix elements",
+		arity:   1,
+		numeric: true,
+		input:   rowsInput,
+		compute: func(args operationArgs) (result, error) {
+			rows := args.operands[0].rows
+			if s := sparseOf(rows, args.storage); s != nil {
+				return scalarResult(s.Product(args.typ))
+			}
+			return scalarResult(matrix.ProductRows(args.typ, rows))
+		},
+	},
+	operation{
+		name:    "determinant",
+		usage:   "the determinant of the square matrix",
+		arity:   1,
+		shape:   squareShape,
+		numeric: true,
+		input:   denseInput,
+		compute: func(args operationArgs) (result, error) {
+			return scalarResult(args.operands[0].dense.Determinant(args.typ))
+		},
+	},
+	operation{
+		name:    "trace",
+		usage:   "the sum of the main diagonal of the square matrix",
+		arity:   1,
+		shape:   squareShape,
+		numeric: true,
+		input:   denseInput,
+		compute: func(args operationArgs) (result, error) {
+			return scalarResult(args.operands[0].dense.Trace(args.typ))
+		},
+	},
+	operation{
+		name:    "matmul",
+		usage:   "the matrix product a·b",
+		arity:   2,
+		numeric: true,
+		input:   storedInput,
+		compute: func(args operationArgs) (result, error) {
+			if a, b := sparseOperands(args); a != nil {
+				return sparseResult(a.Mul(b, args.typ))
+			}
+			return denseResult(args.operands[0].dense.Mul(args.operands[1].dense, args.typ))
+		},
+	},
+	operation{
+		name:    "add",
+		usage:   "the element-wise sum a+b",
+		arity:   2,
+		numeric: true,
+		input:   storedInput,
+		compute: func(args operationArgs) (result, error) {
+			if a, b := sparseOperands(args); a != nil {
+				return sparseResult(a.Add(b, args.typ))
+			}
+			return denseResult(args.operands[0].dense.Add(args.operands[1].dense, args.typ))
+		},
+	},
+	operation{
+		name:    "subtract",
+		usage:   "the element-wise difference a-b",
+		arity:   2,
+		numeric: true,
+		input:   storedInput,
+		compute: func(args operationArgs) (result, error) {
+			if a, b := sparseOperands(args); a != nil {
+				return sparseResult(a.Sub(b, args.typ))
+			}
+			return denseResult(args.operands[0].dense.Sub(args.operands[1].dense, args.typ))
+		},
+	},
+	operation{
+		name:    "hadamard",
+		usage:   "the element-wise product a∘b",
+		arity:   2,
+		numeric: true,
+		input:   denseInput,
+		compute: func(args operationArgs) (result, error) {
+			return denseResult(args.operands[0].dense.Hadamard(args.operands[1].dense, args.typ))
+		},
+	},
+	operation{
+		name:    "divide",
+		usage:   "the element-wise quotient a/b",
+		arity:   2,
+		numeric: true,
+		input:   denseInput,
+		compute: func(args operationArgs) (result, error) {
+			return denseResult(args.operands[0].dense.Div(args.operands[1].dense, args.typ))
+		},
+	},
+}
+
+// sparseOperands gets both stored operands as sparse ones when either of them is sparse, nil otherwise
+func sparseOperands(args operationArgs) (a, b *matrix.SparseText) {
+	if args.operands[0].sparse == nil && args.operands[1].sparse == nil {
+		return nil, nil
+	}
+	return args.operands[0].sparseText(), args.operands[1].sparseText()
+}
@@ -0,0 +1,160 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Entry is the stored element of the sparse matrix, the row and column are 0-based
+type Entry[E any] struct {
+	Row, Col int
+	Value    E
+}
+
+// csr is the compressed sparse row (CSR) storage shared by Sparse and SparseText.
+// Only the stored elements take memory, so the operations take time proportional to their number
+type csr[E any] struct {
+	rows, cols int
+	rowStart   []int // the elements of row i are elems[rowStart[i]:rowStart[i+1]]
+	colIndex   []int // the columns of elems, increasing within each row
+	elems      []E
+}
+
+// newCSR sorts the entries row by row. The entries at the same position are combined with merge,
+// or reported as ErrDuplicateEntry when merge is nil
+func newCSR[E any](rows, cols int, entries []Entry[E], merge func(x, y E) E) (csr[E], error) {
+	if rows <= 0 || cols <= 0 {
+		return csr[E]{}, ErrEmpty
+	}
+	sorted := make([]Entry[E], len(entries))
+	copy(sorted, entries)
+	for _, e := range sorted {
+		if e.Row < 0 || e.Row >= rows || e.Col < 0 || e.Col >= cols {
+			return csr[E]{}, fmt.Errorf("%w: row %d, column %d of %dx%d matrix", ErrOutOfRange, e.Row+1, e.Col+1, rows, cols)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Row != sorted[j].Row {
+			return sorted[i].Row < sorted[j].Row
+		}
+		return sorted[i].Col < sorted[j].Col
+	})
+
+	m := newEmptyCSR[E](rows, cols, len(sorted))
+	for k, e := range sorted {
+		if k > 0 && e.Row == sorted[k-1].Row && e.Col == sorted[k-1].Col {
+			if merge == nil {
+				return csr[E]{}, fmt.Errorf("%w: row %d, column %d", ErrDuplicateEntry, e.Row+1, e.Col+1)
+			}
+			m.elems[len(m.elems)-1] = merge(m.elems[len(m.elems)-1], e.Value)
+			continue
+		}
+		m.colIndex = append(m.colIndex, e.Col)
+		m.elems = append(m.elems, e.Value)
+		m.rowStart[e.Row+1] = len(m.elems)
+	}
+	m.fillRowStarts()
+	return m, nil
+}
+
+// newEmptyCSR makes the storage of the shape without elements, they are appended row by row
+// with rowStart[i+1] set after the last element of row i, see fillRowStarts
+func newEmptyCSR[E any](rows, cols, capacity int) csr[E] {
+	return csr[E]{
+		rows:     rows,
+		cols:     cols,
+		rowStart: make([]int, rows+1),
+		colIndex: make([]int, 0, capacity),
+		elems:    make([]E, 0, capacity),
+	}
+}
+
+// fillRowStarts sets the starts of the rows without elements, which are left zero by the appending
+func (m *csr[E]) fillRowStarts() {
+	for i := 1; i <= m.rows; i++ {
+		if m.rowStart[i] < m.rowStart[i-1] {
+			m.rowStart[i] = m.rowStart[i-1]
+		}
+	}
+}
+
+// Rows gets the number of rows
+func (m *csr[E]) Rows() int {
+	return m.rows
+}
+
+// Cols gets the number of columns
+func (m *csr[E]) Cols() int {
+	return m.cols
+}
+
+// NonZeros gets the number of the stored elements
+func (m *csr[E]) NonZeros() int {
+	return len(m.elems)
+}
+
+// Density gets the share of the stored elements, from 0 to 1
+func (m *csr[E]) Density() float64 {
+	return float64(len(m.elems)) / float64(m.rows) / float64(m.cols)
+}
+
+// IsSquare checks if the number of rows is equal to the number of columns
+func (m *csr[E]) IsSquare() bool {
+	return m.rows == m.cols
+}
+
+// Shape represents the dimensions of matrix as "rows x columns", e.g. "3x2"
+func (m *csr[E]) Shape() string {
+	return fmt.Sprintf("%dx%d", m.rows, m.cols)
+}
+
+// Entries gets the copy of the stored elements row by row
+func (m *csr[E]) Entries() []Entry[E] {
+	entries := make([]Entry[E], 0, len(m.elems))
+	for i := 0; i < m.rows; i++ {
+		for k := m.rowStart[i]; k < m.rowStart[i+1]; k++ {
+			entries = append(entries, Entry[E]{Row: i, Col: m.colIndex[k], Value: m.elems[k]})
+		}
+	}
+	return entries
+}
+
+// find gets the element at row i and column j, ok is false when it isn't stored
+func (m *csr[E]) find(i, j int) (elem E, ok bool) {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(fmt.Sprintf("matrix: index [%d][%d] out of range of %s matrix", i, j, m.Shape()))
+	}
+	start, end := m.rowStart[i], m.rowStart[i+1]
+	k := start + sort.SearchInts(m.colIndex[start:end], j)
+	if k < end && m.colIndex[k] == j {
+		return m.elems[k], true
+	}
+	return elem, false
+}
+
+// transpose swaps the rows and columns with the counting sort by column, the rows of the result stay sorted
+func (m *csr[E]) transpose() csr[E] {
+	t := csr[E]{
+		rows:     m.cols,
+		cols:     m.rows,
+		rowStart: make([]int, m.cols+1),
+		colIndex: make([]int, len(m.elems)),
+		elems:    make([]E, len(m.elems)),
+	}
+	for _, j := range m.colIndex {
+		t.rowStart[j+1]++
+	}
+	for j := 0; j < m.cols; j++ {
+		t.rowStart[j+1] += t.rowStart[j]
+	}
+	next := append([]int(nil), t.rowStart[:m.cols]...)
+	for i := 0; i < m.rows; i++ {
+		for k := m.rowStart[i]; k < m.rowStart[i+1]; k++ {
+			j := m.colIndex[k]
+			t.colIndex[next[j]] = i
+			t.elems[next[j]] = m.elems[k]
+			next[j]++
+		}
+	}
+	return t
+}
@@ -0,0 +1,105 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrEmpty             = errors.New("matrix shouldn't be empty")
+	ErrRagged            = errors.New("all matrix rows should have the same number of elements")
+	ErrNotSquare         = errors.New("matrix should be square")
+	ErrSingular          = errors.New("matrix is singular and has no inverse")
+	ErrDimensionMismatch = errors.New("matrix dimensions mismatch")
+	ErrDivisionByZero    = errors.New("division by zero")
+	ErrOverflow          = errors.New("result doesn't fit into 64-bit integer, int and bigint types have arbitrary precision")
+	ErrUnsupported       = errors.New("operation isn't supported for the numeric type")
+	ErrOutOfRange        = errors.New("matrix entry is out of range")
+	ErrDuplicateEntry    = errors.New("matrix entry is duplicated")
+
+	ErrNonInteger  = errors.New("only integers allowed in matrix")
+	ErrNonInt64    = errors.New("only 64-bit integers allowed in matrix")
+	ErrNonRational = errors.New("only rational numbers allowed in matrix")
+	ErrNonDecimal  = errors.New("only decimal numbers allowed in matrix")
+	ErrNonFloat    = errors.New("only floating-point numbers allowed in matrix")
+	ErrNonComplex  = errors.New("only complex numbers allowed in matrix")
+	ErrUnknownType = errors.New("unknown numeric type, int, int64, bigint, rational, decimal, float and complex allowed")
+)
+
+const (
+	// LeftOperand and RightOperand name the operands of the binary operations in CellError, e.g. a·b
+	LeftOperand  = "a"
+	RightOperand = "b"
+
+	// MaxCellErrors is the number of invalid elements kept by CellErrors, the rest are only counted
+	MaxCellErrors = 100
+)
+
+// CellError is the error of the particular matrix element, the row and column are 1-based
+type CellError struct {
+	Err     error
+	Row     int
+	Col     int
+	Operand string // operand of the binary operation, empty for the single matrix
+	Value   string // empty when the value is too long to be reported
+}
+
+func (e *CellError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Err, e.describe())
+}
+
+func (e *CellError) Unwrap() error {
+	return e.Err
+}
+
+// describe represents the value and location of the element, e.g. "x" at row 1, column 2 of matrix b
+func (e *CellError) describe() string {
+	location := fmt.Sprintf("row %d, column %d", e.Row, e.Col)
+	if e.Operand != "" {
+		location = fmt.Sprintf("%s of matrix %s", location, e.Operand)
+	}
+	if e.Value == "" {
+		return location
+	}
+	return fmt.Sprintf("%q at %s", e.Value, location)
+}
+
+// CellErrors collects the errors of all invalid elements, so they can be fixed at once.
+// All collected errors are expected to wrap the same error, e.g. ErrNonInteger
+type CellErrors struct {
+	Cells []*CellError
+	Total int // number of the added errors, including the dropped ones
+}
+
+// Add collects the error, only the first MaxCellErrors are kept to bound the size of the error
+func (e *CellErrors) Add(cell *CellError) {
+	e.Total++
+	if len(e.Cells) < MaxCellErrors {
+		e.Cells = append(e.Cells, cell)
+	}
+}
+
+// Err returns nil when no errors were collected, so the empty collection isn't mistaken for an error
+func (e *CellErrors) Err() error {
+	if e.Total == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *CellErrors) Error() string {
+	descriptions := make([]string, len(e.Cells))
+	for i, cell := range e.Cells {
+		descriptions[i] = cell.describe()
+	}
+	msg := fmt.Sprintf("%s: %s", e.Cells[0].Err, strings.Join(descriptions, "; "))
+	if e.Total > len(e.Cells) {
+		msg = fmt.Sprintf("%s and %d more", msg, e.Total-len(e.Cells))
+	}
+	return msg
+}
+
+func (e *CellErrors) Unwrap() error {
+	return e.Cells[0].Err
+}
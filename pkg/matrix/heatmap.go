@@ -0,0 +1,248 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+var ErrUnknownColorMap = errors.New("unknown color map, viridis, gray, hot and coolwarm allowed")
+
+// ColorMap maps the values of the heatmap to colors, the smallest value gets the first color and the largest one the last
+type ColorMap string
+
+const (
+	Viridis  ColorMap = "viridis"  // perceptually uniform, from dark purple through teal to yellow
+	Gray     ColorMap = "gray"     // from black to white
+	Hot      ColorMap = "hot"      // from black through red and yellow to white
+	Coolwarm ColorMap = "coolwarm" // diverging, from blue through light gray to red
+)
+
+const (
+	// HeatmapMaxSize is the largest side of the heatmap in pixels, the larger matrices are downsampled
+	HeatmapMaxSize = 1024
+
+	// heatmapMaxCellSize keeps the heatmaps of small matrices small
+	heatmapMaxCellSize = 48
+)
+
+// colorMapStops are the evenly spaced colors of the color maps, the colors between them are interpolated
+var colorMapStops = map[ColorMap][]color.RGBA{
+	Viridis: {
+		{R: 0x44, G: 0x01, B: 0x54, A: 0xff},
+		{R: 0x3b, G: 0x52, B: 0x8b, A: 0xff},
+		{R: 0x21, G: 0x91, B: 0x8c, A: 0xff},
+		{R: 0x5e, G: 0xc9, B: 0x62, A: 0xff},
+		{R: 0xfd, G: 0xe7, B: 0x25, A: 0xff},
+	},
+	Gray: {
+		{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	},
+	Hot: {
+		{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+		{R: 0xff, G: 0x00, B: 0x00, A: 0xff},
+		{R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+		{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	},
+	Coolwarm: {
+		{R: 0x3b, G: 0x4c, B: 0xc0, A: 0xff},
+		{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff},
+		{R: 0xb4, G: 0x04, B: 0x26, A: 0xff},
+	},
+}
+
+// ParseColorMap checks that the color map is known, e.g. "viridis"
+func ParseColorMap(s string) (ColorMap, error) {
+	if _, ok := colorMapStops[ColorMap(s)]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownColorMap, s)
+	}
+	return ColorMap(s), nil
+}
+
+// at gets the color of the value scaled to [0, 1]
+func (c ColorMap) at(x float64) color.RGBA {
+	stops := colorMapStops[c]
+	pos := x * float64(len(stops)-1)
+	k := int(pos)
+	if k >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	frac := pos - float64(k)
+	mix := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*frac)) }
+	from, to := stops[k], stops[k+1]
+	return color.RGBA{R: mix(from.R, to.R), G: mix(from.G, to.G), B: mix(from.B, to.B), A: 0xff}
+}
+
+// HeatmapEncoder draws the matrices as PNG heatmaps, so their structure like bands, blocks and outliers is seen at a glance.
+// The colors are scaled between the smallest and the largest element, the NaN and infinite elements are transparent
+type HeatmapEncoder struct {
+	w        io.Writer
+	colorMap ColorMap
+	labels   bool
+}
+
+// NewHeatmapEncoder returns the encoder writing to w with the viridis color map
+func NewHeatmapEncoder(w io.Writer) *HeatmapEncoder {
+	return &HeatmapEncoder{w: w, colorMap: Viridis}
+}
+
+// SetColorMap sets the color map of the heatmap
+func (e *HeatmapEncoder) SetColorMap(c ColorMap) {
+	e.colorMap = c
+}
+
+// SetLabels writes the values in the cells, when the cells are large enough to fit them
+func (e *HeatmapEncoder) SetLabels(labels bool) {
+	e.labels = labels
+}
+
+// Encode draws the matrix of real numbers, all non-real elements are reported at once with ErrNonFloat.
+// The matrices with more than HeatmapMaxSize rows or columns are downsampled, each pixel is the mean of the block of elements
+func (e *HeatmapEncoder) Encode(m *Text) error {
+	if _, ok := colorMapStops[e.colorMap]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownColorMap, e.colorMap)
+	}
+	values, err := parseReal(m)
+	if err != nil {
+		return err
+	}
+
+	// the block is the square of the elements drawn as one pixel
+	block := (maxInt(m.rows, m.cols) + HeatmapMaxSize - 1) / HeatmapMaxSize
+	rows, cols := (m.rows+block-1)/block, (m.cols+block-1)/block
+	cells := make([]float64, rows*cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			cells[i*cols+j] = blockMean(values, m.cols, i*block, j*block, minInt(block, m.rows-i*block), minInt(block, m.cols-j*block))
+		}
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, x := range cells {
+		if !math.IsNaN(x) && !math.IsInf(x, 0) {
+			low, high = math.Min(low, x), math.Max(high, x)
+		}
+	}
+
+	cellSize := minInt(maxInt(HeatmapMaxSize/maxInt(rows, cols), 1), heatmapMaxCellSize)
+	img := image.NewRGBA(image.Rect(0, 0, cols*cellSize, rows*cellSize))
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			x := cells[i*cols+j]
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				continue
+			}
+			scaled := 0.5 // the matrix of equal elements gets the middle color
+			if high > low {
+				scaled = (x - low) / (high - low)
+			}
+			fill := e.colorMap.at(scaled)
+			cell := image.Rect(j*cellSize, i*cellSize, (j+1)*cellSize, (i+1)*cellSize)
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					img.SetRGBA(x, y, fill)
+				}
+			}
+			if e.labels && block == 1 {
+				drawLabel(img, cell, strconv.FormatFloat(x, 'g', 3, 64), fill)
+			}
+		}
+	}
+	return png.Encode(e.w, img)
+}
+
+// parseReal parses the elements as float64, the fractions like 1/3 are rounded to the nearest float
+func parseReal(m *Text) ([]float64, error) {
+	var errs CellErrors
+	values := make([]float64, len(m.cells))
+	for k, cell := range m.cells {
+		x, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			r, ok := new(big.Rat).SetString(cell)
+			if !ok {
+				errs.Add(&CellError{Err: ErrNonFloat, Row: k/m.cols + 1, Col: k%m.cols + 1, Value: cell})
+				continue
+			}
+			x, _ = r.Float64()
+		}
+		values[k] = x
+	}
+	return values, errs.Err()
+}
+
+// blockMean is the mean of the finite values of the block, NaN when there are none
+func blockMean(values []float64, stride, top, left, height, width int) float64 {
+	sum, count := 0.0, 0
+	for i := top; i < top+height; i++ {
+		for j := left; j < left+width; j++ {
+			if x := values[i*stride+j]; !math.IsNaN(x) && !math.IsInf(x, 0) {
+				sum += x
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return math.NaN()
+	}
+	return sum / float64(count)
+}
+
+// glyphs is the 3x5 pixel font of the labels, each row is 3 bits from the left
+var glyphs = map[rune][5]uint8{
+	'0': {7, 5, 5, 5, 7}, '1': {2, 6, 2, 2, 7}, '2': {7, 1, 7, 4, 7}, '3': {7, 1, 7, 1, 7}, '4': {5, 5, 7, 1, 1},
+	'5': {7, 4, 7, 1, 7}, '6': {7, 4, 7, 5, 7}, '7': {7, 1, 1, 1, 1}, '8': {7, 5, 7, 5, 7}, '9': {7, 5, 7, 1, 7},
+	'-': {0, 0, 7, 0, 0}, '+': {0, 2, 7, 2, 0}, '.': {0, 0, 0, 0, 2}, 'e': {0, 7, 7, 4, 7},
+}
+
+const (
+	glyphWidth   = 3
+	glyphHeight  = 5
+	glyphSpacing = 1
+)
+
+// drawLabel draws the text centered in the cell, scaled up to the largest size fitting the cell with the margin of 1 pixel.
+// The text is black on the light colors and white on the dark ones
+func drawLabel(img *image.RGBA, cell image.Rectangle, text string, background color.RGBA) {
+	width := len(text)*(glyphWidth+glyphSpacing) - glyphSpacing
+	scale := minInt((cell.Dx()-2)/width, (cell.Dy()-2)/glyphHeight)
+	if scale < 1 {
+		return
+	}
+	ink := color.RGBA{A: 0xff}
+	if 299*int(background.R)+587*int(background.G)+114*int(background.B) < 128*1000 {
+		ink = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	}
+
+	left := cell.Min.X + (cell.Dx()-width*scale)/2
+	top := cell.Min.Y + (cell.Dy()-glyphHeight*scale)/2
+	for k, r := range text {
+		glyph := glyphs[r]
+		for y := 0; y < glyphHeight*scale; y++ {
+			for x := 0; x < glyphWidth*scale; x++ {
+				if glyph[y/scale]&(1<<(glyphWidth-1-x/scale)) != 0 {
+					img.SetRGBA(left+k*(glyphWidth+glyphSpacing)*scale+x, top+y, ink)
+				}
+			}
+		}
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
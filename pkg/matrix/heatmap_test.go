@@ -0,0 +1,158 @@
+package matrix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"testing"
+)
+
+func TestHeatmapEncoder_Encode(t *testing.T) {
+	gray := func(y uint8) color.RGBA { return color.RGBA{R: y, G: y, B: y, A: 0xff} }
+	column := make([][]string, 2*HeatmapMaxSize)
+	for i := range column {
+		column[i] = []string{strconv.Itoa(i)}
+	}
+	type args struct {
+		records  [][]string
+		colorMap ColorMap
+		labels   bool
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args       args
+		wantSize   image.Point
+		wantPixels map[image.Point]color.RGBA
+		wantErr    error
+	}{
+		{
+			name: "encode heatmap happy path",
+			when: "the small matrix is drawn with the gray color map",
+			then: "the cells should be scaled up and colored from black for the smallest element to white for the largest one",
+
+			args:     args{records: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}, colorMap: Gray},
+			wantSize: image.Pt(3*heatmapMaxCellSize, 2*heatmapMaxCellSize),
+			wantPixels: map[image.Point]color.RGBA{
+				{X: 0, Y: 0}:    gray(0x00),
+				{X: 47, Y: 47}:  gray(0x00),
+				{X: 48, Y: 0}:   gray(0x33),
+				{X: 96, Y: 0}:   gray(0x66),
+				{X: 0, Y: 48}:   gray(0x99),
+				{X: 48, Y: 48}:  gray(0xcc),
+				{X: 143, Y: 95}: gray(0xff),
+			},
+		},
+		{
+			name: "encode heatmap happy path with default color map",
+			when: "the color map isn't set and the elements are fractions",
+			then: "the ends of viridis should be used",
+
+			args:     args{records: [][]string{{"1/3", "2/3"}}},
+			wantSize: image.Pt(2*heatmapMaxCellSize, heatmapMaxCellSize),
+			wantPixels: map[image.Point]color.RGBA{
+				{X: 0, Y: 0}:  {R: 0x44, G: 0x01, B: 0x54, A: 0xff},
+				{X: 48, Y: 0}: {R: 0xfd, G: 0xe7, B: 0x25, A: 0xff},
+			},
+		},
+		{
+			name: "encode heatmap happy path with equal elements and labels",
+			when: "all elements are equal and the labels are requested",
+			then: "the cells should have the middle color and the dark label",
+
+			args:     args{records: [][]string{{"1"}}, colorMap: Gray, labels: true},
+			wantSize: image.Pt(heatmapMaxCellSize, heatmapMaxCellSize),
+			wantPixels: map[image.Point]color.RGBA{
+				{X: 0, Y: 0}:   gray(0x80),
+				{X: 24, Y: 24}: gray(0x00), // the stem of the "1"
+				{X: 12, Y: 24}: gray(0x80),
+			},
+		},
+		{
+			name: "encode heatmap happy path with NaN",
+			when: "the matrix has NaN element",
+			then: "the NaN cell should be transparent and excluded from the scale",
+
+			args:     args{records: [][]string{{"NaN", "0", "1"}}, colorMap: Hot},
+			wantSize: image.Pt(3*heatmapMaxCellSize, heatmapMaxCellSize),
+			wantPixels: map[image.Point]color.RGBA{
+				{X: 0, Y: 0}:  {},
+				{X: 48, Y: 0}: gray(0x00),
+				{X: 96, Y: 0}: gray(0xff),
+			},
+		},
+		{
+			name: "encode heatmap happy path with downsampling",
+			when: "the matrix has twice as many rows as the max size",
+			then: "each pixel should be the mean of two elements and the labels should be skipped",
+
+			args:     args{records: column, colorMap: Gray, labels: true},
+			wantSize: image.Pt(1, HeatmapMaxSize),
+			wantPixels: map[image.Point]color.RGBA{
+				{X: 0, Y: 0}:                  gray(0x00),
+				{X: 0, Y: HeatmapMaxSize - 1}: gray(0xff),
+			},
+		},
+		{
+			name: "encode heatmap unhappy path with non-real element",
+			when: "the matrix has complex element",
+			then: "error should be returned",
+
+			args:    args{records: [][]string{{"1", "1+2i"}}},
+			wantErr: ErrNonFloat,
+		},
+		{
+			name: "encode heatmap unhappy path with unknown color map",
+			when: "the color map doesn't exist",
+			then: "error should be returned",
+
+			args:    args{records: [][]string{{"1"}}, colorMap: "jet"},
+			wantErr: ErrUnknownColorMap,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewText(tt.args.records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			encoder := NewHeatmapEncoder(&out)
+			if tt.args.colorMap != "" {
+				encoder.SetColorMap(tt.args.colorMap)
+			}
+			encoder.SetLabels(tt.args.labels)
+			err = encoder.Encode(m)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			img, err := png.Decode(&out)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := img.Bounds().Size(); got != tt.wantSize {
+				t.Errorf(errTemplate, meta, got, tt.wantSize)
+			}
+			for p, want := range tt.wantPixels {
+				if got := color.RGBAModel.Convert(img.At(p.X, p.Y)); got != want {
+					t.Errorf(errTemplate, meta, fmt.Sprintf("%v at %v", got, p), want)
+				}
+			}
+		})
+	}
+}
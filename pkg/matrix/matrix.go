@@ -0,0 +1,346 @@
+// Package matrix implements the parsing, validation and operations of matrices.
+//
+// Matrix is the numeric matrix of the element type T, its operations are written once against the arithmetic
+// of T. Text keeps the elements as they were read, so the structural operations like Transpose work with any
+// elements, and its arithmetic operations parse the elements as the requested numeric Type.
+// All invalid elements are reported at once with CellErrors.
+package matrix
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// Number is the element type of the numeric matrix
+type Number interface {
+	int64 | float64 | complex128 | *big.Int | *big.Rat
+}
+
+// Matrix is the non-empty rectangular numeric matrix, it's never modified after construction.
+// The operations on int64 elements return ErrOverflow when the result doesn't fit, *big.Int and *big.Rat are exact
+type Matrix[T Number] struct {
+	rows, cols int
+	elems      []T // row by row
+	ar         arithmetic[T]
+}
+
+// New makes the matrix of the elements, which should be non-empty and have the same number of elements in each row.
+// The elements are copied, so they can be reused by the caller
+func New[T Number](elems [][]T) (*Matrix[T], error) {
+	if len(elems) == 0 || len(elems[0]) == 0 {
+		return nil, ErrEmpty
+	}
+	m := newMatrix(arithmeticOf[T](), len(elems), len(elems[0]))
+	for i := range elems {
+		if len(elems[i]) != m.cols {
+			return nil, fmt.Errorf("%w: row %d has %d elements, but row 1 has %d",
+				ErrRagged, i+1, len(elems[i]), m.cols)
+		}
+		m.elems = append(m.elems, elems[i]...)
+	}
+	return m, nil
+}
+
+// Parse reads the matrix of the element type T from CSV, e.g. Parse[int64](r)
+func Parse[T Number](r io.Reader) (*Matrix[T], error) {
+	t, err := ParseText(r)
+	if err != nil {
+		return nil, err
+	}
+	return FromText[T](t)
+}
+
+// FromText parses the elements of the text matrix as T, all invalid elements are reported at once
+func FromText[T Number](t *Text) (*Matrix[T], error) {
+	return parseMatrix(arithmeticOf[T](), t)
+}
+
+// newMatrix makes the matrix of the shape without elements, they are appended row by row
+func newMatrix[T Number](ar arithmetic[T], rows, cols int) *Matrix[T] {
+	return &Matrix[T]{rows: rows, cols: cols, elems: make([]T, 0, rows*cols), ar: ar}
+}
+
+// Rows gets the number of rows
+func (m *Matrix[T]) Rows() int {
+	return m.rows
+}
+
+// Cols gets the number of columns
+func (m *Matrix[T]) Cols() int {
+	return m.cols
+}
+
+// At gets the element at row i and column j, both 0-based
+func (m *Matrix[T]) At(i, j int) T {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(fmt.Sprintf("matrix: index [%d][%d] out of range of %s matrix", i, j, m.Shape()))
+	}
+	return m.elems[i*m.cols+j]
+}
+
+// IsSquare checks if the number of rows is equal to the number of columns
+func (m *Matrix[T]) IsSquare() bool {
+	return m.rows == m.cols
+}
+
+// Shape represents the dimensions of matrix as "rows x columns", e.g. "3x2"
+func (m *Matrix[T]) Shape() string {
+	return fmt.Sprintf("%dx%d", m.rows, m.cols)
+}
+
+// Text formats the elements of the matrix
+func (m *Matrix[T]) Text() *Text {
+	t := &Text{rows: m.rows, cols: m.cols, cells: make([]string, len(m.elems))}
+	for i := range m.elems {
+		t.cells[i] = m.ar.format(m.elems[i])
+	}
+	return t
+}
+
+// String represents the matrix as CSV, one line per row
+func (m *Matrix[T]) String() string {
+	return m.Text().String()
+}
+
+// Transpose swaps the rows and columns of the matrix
+func (m *Matrix[T]) Transpose() *Matrix[T] {
+	transposed := &Matrix[T]{rows: m.cols, cols: m.rows, elems: make([]T, len(m.elems)), ar: m.ar}
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			transposed.elems[j*transposed.cols+i] = m.elems[i*m.cols+j]
+		}
+	}
+	return transposed
+}
+
+// Sum gets the sum of the elements
+func (m *Matrix[T]) Sum() (T, error) {
+	ar := newCalculation(m.ar)
+	sum := ar.zero()
+	for _, elem := range m.elems {
+		sum = ar.add(sum, elem)
+	}
+	return sum, ar.err
+}
+
+// Product gets the product of the elements
+func (m *Matrix[T]) Product() (T, error) {
+	ar := newCalculation(m.ar)
+	product := ar.one()
+	for _, elem := range m.elems {
+		product = ar.mul(product, elem)
+	}
+	return product, ar.err
+}
+
+// Trace gets the sum of the main diagonal elements of the square matrix
+func (m *Matrix[T]) Trace() (T, error) {
+	ar := newCalculation(m.ar)
+	trace := ar.zero()
+	if !m.IsSquare() {
+		return trace, ErrNotSquare
+	}
+	for i := 0; i < m.rows; i++ {
+		trace = ar.add(trace, m.At(i, i))
+	}
+	return trace, ar.err
+}
+
+// Determinant computes the determinant of the square matrix with the fraction-free Bareiss algorithm,
+// so for integer matrices every intermediate value stays an exact integer
+func (m *Matrix[T]) Determinant() (T, error) {
+	ar := newCalculation(m.ar)
+	if !m.IsSquare() {
+		return ar.zero(), ErrNotSquare
+	}
+	a := m.rowsCopy()
+
+	n := len(a)
+	negate := false
+	prevPivot := ar.one()
+	for k := 0; k < n-1; k++ {
+		if ar.isZero(a[k][k]) {
+			// find a row with a non-zero pivot, every swap flips the sign of the determinant
+			swap := -1
+			for row := k + 1; row < n; row++ {
+				if !ar.isZero(a[row][k]) {
+					swap = row
+					break
+				}
+			}
+			if swap == -1 {
+				return ar.zero(), nil
+			}
+			a[k], a[swap] = a[swap], a[k]
+			negate = !negate
+		}
+		for i := k + 1; i < n; i++ {
+			for j := k + 1; j < n; j++ {
+				// a[i][j] = (a[i][j]*a[k][k] - a[i][k]*a[k][j]) / prevPivot, the division is always exact
+				a[i][j] = ar.quo(ar.sub(ar.mul(a[i][j], a[k][k]), ar.mul(a[i][k], a[k][j])), prevPivot)
+			}
+		}
+		prevPivot = a[k][k]
+	}
+
+	det := a[n-1][n-1]
+	if negate {
+		det = ar.sub(ar.zero(), det)
+	}
+	return det, ar.err
+}
+
+// Inverse computes the inverse of the square matrix with Gauss-Jordan elimination.
+// It needs the exact division, so it isn't supported for the integer element types
+func (m *Matrix[T]) Inverse() (*Matrix[T], error) {
+	ar := newCalculation(m.ar)
+	if !m.IsSquare() {
+		return nil, ErrNotSquare
+	}
+	if !ar.field() {
+		return nil, fmt.Errorf("%w: inverse needs the exact division, which the integer elements don't have", ErrUnsupported)
+	}
+
+	// build the augmented matrix [A | I]
+	n := m.rows
+	augmented := make([][]T, n)
+	for i := range augmented {
+		augmented[i] = make([]T, 2*n)
+		for j := 0; j < n; j++ {
+			augmented[i][j] = m.At(i, j)
+			augmented[i][n+j] = ar.zero()
+		}
+		augmented[i][n+i] = ar.one()
+	}
+
+	for col := 0; col < n; col++ {
+		// the largest pivot keeps the floating-point elimination stable, any non-zero one would do for exact types
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if ar.cmpAbs(augmented[row][col], augmented[pivot][col]) > 0 {
+				pivot = row
+			}
+		}
+		if ar.isZero(augmented[pivot][col]) {
+			return nil, ErrSingular
+		}
+		augmented[col], augmented[pivot] = augmented[pivot], augmented[col]
+
+		// normalize the pivot row, so the pivot becomes 1
+		pivotValue := augmented[col][col]
+		for j := range augmented[col] {
+			augmented[col][j] = ar.quo(augmented[col][j], pivotValue)
+		}
+
+		// eliminate the pivot column in all other rows
+		for row := 0; row < n; row++ {
+			if row == col || ar.isZero(augmented[row][col]) {
+				continue
+			}
+			factor := augmented[row][col]
+			for j := range augmented[row] {
+				augmented[row][j] = ar.sub(augmented[row][j], ar.mul(factor, augmented[col][j]))
+			}
+		}
+	}
+
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	inverse := newMatrix(m.ar, n, n)
+	for i := range augmented {
+		inverse.elems = append(inverse.elems, augmented[i][n:]...)
+	}
+	return inverse, nil
+}
+
+// Mul computes the matrix product m·b, the number of columns of m should match the number of rows of b
+func (m *Matrix[T]) Mul(b *Matrix[T]) (*Matrix[T], error) {
+	if m.cols != b.rows {
+		return nil, fmt.Errorf("%w: can't multiply %s matrix by %s matrix, columns of the first should match rows of the second",
+			ErrDimensionMismatch, m.Shape(), b.Shape())
+	}
+	ar := newCalculation(m.ar)
+	product := newMatrix(m.ar, m.rows, b.cols)
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < b.cols; j++ {
+			cell := ar.zero()
+			for k := 0; k < m.cols; k++ {
+				cell = ar.add(cell, ar.mul(m.At(i, k), b.At(k, j)))
+			}
+			product.elems = append(product.elems, cell)
+		}
+	}
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	return product, nil
+}
+
+// Add computes the element-wise sum m+b of matrices of the same shape
+func (m *Matrix[T]) Add(b *Matrix[T]) (*Matrix[T], error) {
+	return m.combine(b, (*calculation[T]).add)
+}
+
+// Sub computes the element-wise difference m-b of matrices of the same shape
+func (m *Matrix[T]) Sub(b *Matrix[T]) (*Matrix[T], error) {
+	return m.combine(b, (*calculation[T]).sub)
+}
+
+// Hadamard computes the element-wise (Hadamard) product m∘b of matrices of the same shape
+func (m *Matrix[T]) Hadamard(b *Matrix[T]) (*Matrix[T], error) {
+	return m.combine(b, (*calculation[T]).mul)
+}
+
+// Div computes the element-wise quotient m/b of matrices of the same shape, all zero divisors are reported at once.
+// The integer elements are divided with truncation toward zero, like Go integers
+func (m *Matrix[T]) Div(b *Matrix[T]) (*Matrix[T], error) {
+	if err := checkSameShape(m, b); err != nil {
+		return nil, err
+	}
+	var errs CellErrors
+	for i, elem := range b.elems {
+		if b.ar.isZero(elem) {
+			errs.Add(&CellError{Err: ErrDivisionByZero, Row: i/b.cols + 1, Col: i%b.cols + 1, Operand: RightOperand, Value: b.ar.format(elem)})
+		}
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return m.combine(b, (*calculation[T]).quo)
+}
+
+// combine applies op to each pair of elements of matrices of the same shape
+func (m *Matrix[T]) combine(b *Matrix[T], op func(ar *calculation[T], x, y T) T) (*Matrix[T], error) {
+	if err := checkSameShape(m, b); err != nil {
+		return nil, err
+	}
+	ar := newCalculation(m.ar)
+	combined := newMatrix(m.ar, m.rows, m.cols)
+	for i := range m.elems {
+		combined.elems = append(combined.elems, op(ar, m.elems[i], b.elems[i]))
+	}
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	return combined, nil
+}
+
+// rowsCopy copies the elements into rows, so the elimination algorithms can swap and modify them
+func (m *Matrix[T]) rowsCopy() [][]T {
+	rows := make([][]T, m.rows)
+	for i := range rows {
+		rows[i] = append([]T(nil), m.elems[i*m.cols:(i+1)*m.cols]...)
+	}
+	return rows
+}
+
+// checkSameShape checks that the element-wise operation can be applied to both matrices
+func checkSameShape[T Number](a, b *Matrix[T]) error {
+	if a.rows != b.rows || a.cols != b.cols {
+		return fmt.Errorf("%w: can't combine %s matrix with %s matrix element-wise, shapes should be equal",
+			ErrDimensionMismatch, a.Shape(), b.Shape())
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	type args struct {
+		elems [][]int64
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "new matrix happy path",
+			when: "all rows have the same number of elements",
+			then: "the matrix of the elements should be returned",
+
+			args: args{elems: [][]int64{{1, 2, 3}, {4, 5, 6}}},
+			want: "1,2,3\n4,5,6\n",
+		},
+		{
+			name: "new matrix unhappy path with empty elements",
+			when: "there are no elements",
+			then: "error should be returned",
+
+			args:    args{elems: [][]int64{}},
+			wantErr: ErrEmpty,
+		},
+		{
+			name: "new matrix unhappy path with ragged rows",
+			when: "the rows have different number of elements",
+			then: "error should be returned",
+
+			args:    args{elems: [][]int64{{1, 2}, {3}}},
+			wantErr: ErrRagged,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.args.elems)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf(errTemplate, meta, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		parse   func() (fmt.Stringer, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "parse matrix happy path with big integers",
+			when: "the elements don't fit into int64",
+			then: "the exact matrix should be returned",
+
+			parse: func() (fmt.Stringer, error) {
+				return Parse[*big.Int](strings.NewReader("123456789012345678901234567890,1\n"))
+			},
+			want: "123456789012345678901234567890,1\n",
+		},
+		{
+			name: "parse matrix happy path with complex numbers",
+			when: "the elements are complex and real numbers",
+			then: "the complex matrix should be returned",
+
+			parse: func() (fmt.Stringer, error) {
+				return Parse[complex128](strings.NewReader("1+2i,3\n-1.5i,0\n"))
+			},
+			want: "1+2i,3\n0-1.5i,0\n",
+		},
+		{
+			name: "parse matrix unhappy path with int64",
+			when: "the element doesn't fit into int64",
+			then: "error should be returned",
+
+			parse: func() (fmt.Stringer, error) {
+				return Parse[int64](strings.NewReader("1,9223372036854775808\n"))
+			},
+			wantErr: ErrNonInt64,
+		},
+		{
+			name: "parse matrix unhappy path with rationals",
+			when: "the element isn't a number",
+			then: "error should be returned",
+
+			parse: func() (fmt.Stringer, error) {
+				return Parse[*big.Rat](strings.NewReader("1/2,x\n"))
+			},
+			wantErr: ErrNonRational,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.parse()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf(errTemplate, meta, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMatrix_operations(t *testing.T) {
+	ints := mustNewOf([][]int64{{2, -1}, {-1, 2}})
+	bigInts := mustNewOf([][]*big.Int{{big.NewInt(math.MaxInt64), big.NewInt(1)}, {big.NewInt(-1), big.NewInt(math.MaxInt64)}})
+	rats := mustNewOf([][]*big.Rat{{big.NewRat(2, 1), big.NewRat(-1, 1)}, {big.NewRat(-1, 1), big.NewRat(2, 1)}})
+	floats := mustNewOf([][]float64{{0.5, 0}, {0, 4}})
+	complexes := mustNewOf([][]complex128{{1i, 0}, {0, 2}})
+
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		run     func() (any, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "sum happy path with int64",
+			when: "the matrix consists int64 elements",
+			then: "the int64 sum should be returned",
+
+			run:  func() (any, error) { return ints.Sum() },
+			want: "2",
+		},
+		{
+			name: "product happy path with big integers",
+			when: "the product doesn't fit into int64",
+			then: "the exact product should be returned",
+
+			run:  func() (any, error) { return bigInts.Product() },
+			want: "-85070591730234615847396907784232501249",
+		},
+		{
+			name: "determinant happy path with big integers",
+			when: "the determinant doesn't fit into int64",
+			then: "the exact determinant should be returned",
+
+			run:  func() (any, error) { return bigInts.Determinant() },
+			want: "85070591730234615847396907784232501250",
+		},
+		{
+			name: "determinant happy path with int64",
+			when: "the elimination divides by the previous pivot",
+			then: "the exact determinant should be returned",
+
+			run:  func() (any, error) { return mustNewOf([][]int64{{2, -1, 0}, {-1, 2, -1}, {0, -1, 2}}).Determinant() },
+			want: "4",
+		},
+		{
+			name: "sum unhappy path with int64 overflow",
+			when: "the sum doesn't fit into int64",
+			then: "error should be returned instead of the wrapped sum",
+
+			run:     func() (any, error) { return mustNewOf([][]int64{{math.MaxInt64, 1}}).Sum() },
+			wantErr: ErrOverflow,
+		},
+		{
+			name: "determinant unhappy path with int64 overflow",
+			when: "the elimination product doesn't fit into int64",
+			then: "error should be returned instead of the wrapped determinant",
+
+			run:     func() (any, error) { return mustNewOf([][]int64{{math.MaxInt64, 1}, {1, 2}}).Determinant() },
+			wantErr: ErrOverflow,
+		},
+		{
+			name: "trace happy path with complex numbers",
+			when: "the matrix consists complex elements",
+			then: "the complex trace should be returned",
+
+			run:  func() (any, error) { return complexes.Trace() },
+			want: "(2+1i)",
+		},
+		{
+			name: "inverse happy path with rationals",
+			when: "the matrix is invertible",
+			then: "the exact inverse should be returned",
+
+			run:  func() (any, error) { return rats.Inverse() },
+			want: "2/3,1/3\n1/3,2/3\n",
+		},
+		{
+			name: "inverse happy path with floats",
+			when: "the matrix is invertible",
+			then: "the float inverse should be returned",
+
+			run:  func() (any, error) { return floats.Inverse() },
+			want: "2,0\n0,0.25\n",
+		},
+		{
+			name: "inverse unhappy path with int64",
+			when: "the integer elements have no exact division",
+			then: "error should be returned",
+
+			run:     func() (any, error) { return ints.Inverse() },
+			wantErr: ErrUnsupported,
+		},
+		{
+			name: "transpose and multiply happy path with int64",
+			when: "the matrix is multiplied by its transpose",
+			then: "the product should be returned",
+
+			run:  func() (any, error) { return ints.Mul(ints.Transpose()) },
+			want: "5,-4\n-4,5\n",
+		},
+		{
+			name: "add unhappy path with floats",
+			when: "the shapes of the matrices differ",
+			then: "error should be returned",
+
+			run:     func() (any, error) { return floats.Add(mustNewOf([][]float64{{1, 2}})) },
+			wantErr: ErrDimensionMismatch,
+		},
+		{
+			name: "divide happy path with int64",
+			when: "the quotients aren't integers",
+			then: "the quotients truncated toward zero should be returned",
+
+			run:  func() (any, error) { return ints.Div(mustNewOf([][]int64{{4, 2}, {2, 4}})) },
+			want: "0,0\n0,0\n",
+		},
+		{
+			name: "divide unhappy path with int64 overflow",
+			when: "the smallest int64 is divided by -1",
+			then: "error should be returned instead of the wrapped quotient",
+
+			run:     func() (any, error) { return mustNewOf([][]int64{{math.MinInt64}}).Div(mustNewOf([][]int64{{-1}})) },
+			wantErr: ErrOverflow,
+		},
+		{
+			name: "divide unhappy path with complex numbers",
+			when: "the divisor has zero elements",
+			then: "error should be returned",
+
+			run:     func() (any, error) { return complexes.Div(complexes) },
+			wantErr: ErrDivisionByZero,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.run()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if formatted := fmt.Sprint(got); formatted != tt.want {
+				t.Errorf(errTemplate, meta, formatted, tt.want)
+			}
+		})
+	}
+}
+
+// mustNewOf makes the matrix of the valid test elements
+func mustNewOf[T Number](elems [][]T) *Matrix[T] {
+	m, err := New(elems)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
@@ -0,0 +1,525 @@
+package matrix
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"strconv"
+	"strings"
+)
+
+const (
+	// decimalMaxScale is the number of fractional digits the non-terminating decimals are rounded to, e.g. 1/3
+	decimalMaxScale = 20
+)
+
+// Type is the numeric type the elements of Text are parsed as by its arithmetic operations
+type Type string
+
+const (
+	Int      Type = "int"      // arbitrary precision integers, exact fractions for division and inverse
+	Int64    Type = "int64"    // int64, ErrOverflow when the result doesn't fit, truncated division
+	BigInt   Type = "bigint"   // arbitrary precision integers, truncated division
+	Rational Type = "rational" // exact fractions, e.g. 3/4 or 1.5
+	Decimal  Type = "decimal"  // arbitrary precision decimals, e.g. 1.5 or 2e3
+	Float    Type = "float"    // float64
+	Complex  Type = "complex"  // complex128, e.g. 1+2i
+)
+
+// ParseType checks that the numeric type exists
+func ParseType(s string) (Type, error) {
+	t := Type(s)
+	if _, err := t.operations(); err != nil {
+		return "", err
+	}
+	return t, nil
+}
+
+// arithmetic implements parsing, formatting and arithmetic of the matrix elements of type T.
+// The operations never modify their arguments
+type arithmetic[T any] interface {
+	parse(s string) (T, error)
+	format(x T) string
+
+	zero() T
+	one() T
+	add(x, y T) T
+	sub(x, y T) T
+	mul(x, y T) T
+	// quo divides x by y, y is never zero. The integers are divided with truncation
+	quo(x, y T) T
+	// field reports if quo is the exact division, so every non-zero element has the inverse
+	field() bool
+
+	isZero(x T) bool
+	// cmpAbs compares the absolute values of x and y
+	cmpAbs(x, y T) int
+}
+
+// checkedArithmetic is implemented by the arithmetic of the fixed-size integers, ok is false when the result
+// doesn't fit into T
+type checkedArithmetic[T any] interface {
+	addChecked(x, y T) (res T, ok bool)
+	subChecked(x, y T) (res T, ok bool)
+	mulChecked(x, y T) (res T, ok bool)
+	quoChecked(x, y T) (res T, ok bool)
+}
+
+// calculation is the arithmetic of one operation, it keeps the first overflow of the checked arithmetic in err,
+// so the operation returns ErrOverflow instead of the wrapped result. The operations compute with the calculation
+// rather than with the arithmetic of the matrix
+type calculation[T any] struct {
+	arithmetic[T]
+	checked checkedArithmetic[T] // nil when the results always fit
+	err     error
+}
+
+func newCalculation[T any](ar arithmetic[T]) *calculation[T] {
+	checked, _ := ar.(checkedArithmetic[T])
+	return &calculation[T]{arithmetic: ar, checked: checked}
+}
+
+func (c *calculation[T]) add(x, y T) T {
+	if c.checked == nil {
+		return c.arithmetic.add(x, y)
+	}
+	return c.result(c.checked.addChecked(x, y))
+}
+
+func (c *calculation[T]) sub(x, y T) T {
+	if c.checked == nil {
+		return c.arithmetic.sub(x, y)
+	}
+	return c.result(c.checked.subChecked(x, y))
+}
+
+func (c *calculation[T]) mul(x, y T) T {
+	if c.checked == nil {
+		return c.arithmetic.mul(x, y)
+	}
+	return c.result(c.checked.mulChecked(x, y))
+}
+
+func (c *calculation[T]) quo(x, y T) T {
+	if c.checked == nil {
+		return c.arithmetic.quo(x, y)
+	}
+	return c.result(c.checked.quoChecked(x, y))
+}
+
+// result keeps the first overflow, the computation goes on with the wrapped result
+func (c *calculation[T]) result(x T, ok bool) T {
+	if !ok && c.err == nil {
+		c.err = ErrOverflow
+	}
+	return x
+}
+
+// arithmeticOf gets the default arithmetic of the element type
+func arithmeticOf[T Number]() arithmetic[T] {
+	var ar any
+	switch any(*new(T)).(type) {
+	case int64:
+		ar = int64Arithmetic{}
+	case float64:
+		ar = floatArithmetic{}
+	case complex128:
+		ar = complexArithmetic{}
+	case *big.Int:
+		ar = bigIntArithmetic{}
+	case *big.Rat:
+		ar = rationalArithmetic{}
+	}
+	return ar.(arithmetic[T])
+}
+
+// operations are the operations bound to the particular numeric type, with the results formatted as text
+type operations struct {
+	sum         func(rows RowReader) (string, error)
+	multiply    func(rows RowReader) (string, error)
+	trace       func(m *Text) (string, error)
+	determinant func(m *Text) (string, error)
+	inverse     func(m *Text) (*Text, error)
+
+	matmul   func(a, b *Text) (*Text, error)
+	add      func(a, b *Text) (*Text, error)
+	subtract func(a, b *Text) (*Text, error)
+	hadamard func(a, b *Text) (*Text, error)
+	divide   func(a, b *Text) (*Text, error)
+
+	sparseSum      func(m *SparseText) (string, error)
+	sparseProduct  func(m *SparseText) (string, error)
+	sparseMatmul   func(a, b *SparseText) (*SparseText, error)
+	sparseAdd      func(a, b *SparseText) (*SparseText, error)
+	sparseSubtract func(a, b *SparseText) (*SparseText, error)
+}
+
+var types = map[Type]operations{
+	Int:      newOperations[*big.Rat](intArithmetic{}),
+	Int64:    newOperations[int64](int64Arithmetic{}),
+	BigInt:   newOperations[*big.Int](bigIntArithmetic{}),
+	Rational: newOperations[*big.Rat](rationalArithmetic{}),
+	Decimal:  newOperations[*big.Rat](decimalArithmetic{}),
+	Float:    newOperations[float64](floatArithmetic{}),
+	Complex:  newOperations[complex128](complexArithmetic{}),
+}
+
+func (t Type) operations() (operations, error) {
+	ops, ok := types[t]
+	if !ok {
+		return operations{}, fmt.Errorf("%w: %q", ErrUnknownType, string(t))
+	}
+	return ops, nil
+}
+
+// newOperations binds the operations of Matrix to the arithmetic, the elements are parsed and formatted with ar
+func newOperations[T Number](ar arithmetic[T]) operations {
+	fold := func(op func(arithmetic[T], RowReader) (T, error)) func(RowReader) (string, error) {
+		return func(rows RowReader) (string, error) {
+			res, err := op(ar, rows)
+			if err != nil {
+				return "", err
+			}
+			return ar.format(res), nil
+		}
+	}
+	scalar := func(op func(*Matrix[T]) (T, error)) func(*Text) (string, error) {
+		return func(t *Text) (string, error) {
+			m, err := parseMatrix(ar, t)
+			if err != nil {
+				return "", err
+			}
+			res, err := op(m)
+			if err != nil {
+				return "", err
+			}
+			return ar.format(res), nil
+		}
+	}
+	binary := func(op func(a, b *Matrix[T]) (*Matrix[T], error)) func(a, b *Text) (*Text, error) {
+		return func(a, b *Text) (*Text, error) {
+			matrixA, matrixB, err := parseOperands(ar, a, b)
+			if err != nil {
+				return nil, err
+			}
+			res, err := op(matrixA, matrixB)
+			if err != nil {
+				return nil, err
+			}
+			return res.Text(), nil
+		}
+	}
+	sparseScalar := func(op func(*Sparse[T]) (T, error)) func(*SparseText) (string, error) {
+		return func(t *SparseText) (string, error) {
+			m, err := parseSparseMatrix(ar, t)
+			if err != nil {
+				return "", err
+			}
+			res, err := op(m)
+			if err != nil {
+				return "", err
+			}
+			return ar.format(res), nil
+		}
+	}
+	sparseBinary := func(op func(a, b *Sparse[T]) (*Sparse[T], error)) func(a, b *SparseText) (*SparseText, error) {
+		return func(a, b *SparseText) (*SparseText, error) {
+			matrixA, matrixB, err := parseSparseOperands(ar, a, b)
+			if err != nil {
+				return nil, err
+			}
+			res, err := op(matrixA, matrixB)
+			if err != nil {
+				return nil, err
+			}
+			return res.Text(), nil
+		}
+	}
+	return operations{
+		sum:         fold(sumRows[T]),
+		multiply:    fold(multiplyRows[T]),
+		trace:       scalar((*Matrix[T]).Trace),
+		determinant: scalar((*Matrix[T]).Determinant),
+		inverse: func(t *Text) (*Text, error) {
+			m, err := parseMatrix(ar, t)
+			if err != nil {
+				return nil, err
+			}
+			res, err := m.Inverse()
+			if err != nil {
+				return nil, err
+			}
+			return res.Text(), nil
+		},
+
+		matmul:   binary((*Matrix[T]).Mul),
+		add:      binary((*Matrix[T]).Add),
+		subtract: binary((*Matrix[T]).Sub),
+		hadamard: binary((*Matrix[T]).Hadamard),
+		divide:   binary((*Matrix[T]).Div),
+
+		sparseSum:      sparseScalar((*Sparse[T]).Sum),
+		sparseProduct:  sparseScalar((*Sparse[T]).Product),
+		sparseMatmul:   sparseBinary((*Sparse[T]).Mul),
+		sparseAdd:      sparseBinary((*Sparse[T]).Add),
+		sparseSubtract: sparseBinary((*Sparse[T]).Sub),
+	}
+}
+
+// ratArithmetic implements the exact arithmetic over big.Rat, shared by the int, rational and decimal types
+type ratArithmetic struct{}
+
+func (ratArithmetic) zero() *big.Rat             { return new(big.Rat) }
+func (ratArithmetic) one() *big.Rat              { return big.NewRat(1, 1) }
+func (ratArithmetic) add(x, y *big.Rat) *big.Rat { return new(big.Rat).Add(x, y) }
+func (ratArithmetic) sub(x, y *big.Rat) *big.Rat { return new(big.Rat).Sub(x, y) }
+func (ratArithmetic) mul(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) }
+func (ratArithmetic) quo(x, y *big.Rat) *big.Rat { return new(big.Rat).Quo(x, y) }
+func (ratArithmetic) field() bool                { return true }
+func (ratArithmetic) isZero(x *big.Rat) bool     { return x.Sign() == 0 }
+func (ratArithmetic) cmpAbs(x, y *big.Rat) int   { return new(big.Rat).Abs(x).Cmp(new(big.Rat).Abs(y)) }
+
+// intArithmetic accepts integers only, the non-integer results are formatted as exact fractions, e.g. "3/4"
+type intArithmetic struct {
+	ratArithmetic
+}
+
+func (intArithmetic) parse(s string) (*big.Rat, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, ErrNonInteger
+	}
+	return new(big.Rat).SetInt(n), nil
+}
+
+func (intArithmetic) format(x *big.Rat) string {
+	return x.RatString()
+}
+
+// rationalArithmetic accepts fractions and decimals, the results are formatted as exact fractions
+type rationalArithmetic struct {
+	ratArithmetic
+}
+
+func (rationalArithmetic) parse(s string) (*big.Rat, error) {
+	x, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, ErrNonRational
+	}
+	return x, nil
+}
+
+func (rationalArithmetic) format(x *big.Rat) string {
+	return x.RatString()
+}
+
+// decimalArithmetic accepts decimal numbers, the results are formatted as exact decimals when they terminate
+// and rounded to decimalMaxScale fractional digits otherwise
+type decimalArithmetic struct {
+	ratArithmetic
+}
+
+func (decimalArithmetic) parse(s string) (*big.Rat, error) {
+	// big.Rat also accepts fractions like "1/3", which aren't decimals
+	if strings.Contains(s, "/") {
+		return nil, ErrNonDecimal
+	}
+	x, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, ErrNonDecimal
+	}
+	return x, nil
+}
+
+func (decimalArithmetic) format(x *big.Rat) string {
+	if x.IsInt() {
+		return x.Num().String()
+	}
+
+	// the decimal terminates when the denominator has no prime factors other than 2 and 5,
+	// and the number of fractional digits is the largest power of these factors
+	denom := new(big.Int).Set(x.Denom())
+	scale := divideOutFactor(denom, 2)
+	if fives := divideOutFactor(denom, 5); fives > scale {
+		scale = fives
+	}
+	if denom.Cmp(big.NewInt(1)) == 0 && scale <= decimalMaxScale {
+		return x.FloatString(scale)
+	}
+
+	rounded := strings.TrimRight(x.FloatString(decimalMaxScale), "0")
+	return strings.TrimSuffix(rounded, ".")
+}
+
+// divideOutFactor divides n by the prime as many times as possible and returns the number of divisions
+func divideOutFactor(n *big.Int, prime int64) int {
+	var count int
+	p := big.NewInt(prime)
+	quo, rem := new(big.Int), new(big.Int)
+	for {
+		quo.QuoRem(n, p, rem)
+		if rem.Sign() != 0 {
+			return count
+		}
+		n.Set(quo)
+		count++
+	}
+}
+
+// floatArithmetic implements the float64 arithmetic
+type floatArithmetic struct{}
+
+func (floatArithmetic) parse(s string) (float64, error) {
+	x, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, ErrNonFloat
+	}
+	return x, nil
+}
+
+func (floatArithmetic) format(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+func (floatArithmetic) zero() float64            { return 0 }
+func (floatArithmetic) one() float64             { return 1 }
+func (floatArithmetic) add(x, y float64) float64 { return x + y }
+func (floatArithmetic) sub(x, y float64) float64 { return x - y }
+func (floatArithmetic) mul(x, y float64) float64 { return x * y }
+func (floatArithmetic) quo(x, y float64) float64 { return x / y }
+func (floatArithmetic) field() bool              { return true }
+func (floatArithmetic) isZero(x float64) bool    { return x == 0 }
+func (floatArithmetic) cmpAbs(x, y float64) int {
+	ax, ay := math.Abs(x), math.Abs(y)
+	switch {
+	case ax < ay:
+		return -1
+	case ax > ay:
+		return 1
+	}
+	return 0
+}
+
+// int64Arithmetic implements the int64 arithmetic. The operations compute with its checked methods,
+// see calculation, while add, sub, mul and quo wrap around on overflow like Go integers
+type int64Arithmetic struct{}
+
+func (int64Arithmetic) parse(s string) (int64, error) {
+	x, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, ErrNonInt64
+	}
+	return x, nil
+}
+
+func (int64Arithmetic) format(x int64) string {
+	return strconv.FormatInt(x, 10)
+}
+
+func (int64Arithmetic) zero() int64          { return 0 }
+func (int64Arithmetic) one() int64           { return 1 }
+func (int64Arithmetic) add(x, y int64) int64 { return x + y }
+func (int64Arithmetic) sub(x, y int64) int64 { return x - y }
+func (int64Arithmetic) mul(x, y int64) int64 { return x * y }
+func (int64Arithmetic) quo(x, y int64) int64 { return x / y }
+func (int64Arithmetic) field() bool          { return false }
+func (int64Arithmetic) isZero(x int64) bool  { return x == 0 }
+func (int64Arithmetic) cmpAbs(x, y int64) int {
+	// the absolute value of math.MinInt64 doesn't fit into int64, so it's compared as unsigned
+	ax, ay := uint64(x), uint64(y)
+	if x < 0 {
+		ax = -ax
+	}
+	if y < 0 {
+		ay = -ay
+	}
+	switch {
+	case ax < ay:
+		return -1
+	case ax > ay:
+		return 1
+	}
+	return 0
+}
+
+func (int64Arithmetic) addChecked(x, y int64) (int64, bool) {
+	s := x + y
+	// the sum of the same sign operands overflows when its sign differs
+	return s, (x < 0) != (y < 0) || (s < 0) == (x < 0)
+}
+
+func (int64Arithmetic) subChecked(x, y int64) (int64, bool) {
+	d := x - y
+	// the difference of the opposite sign operands overflows when its sign differs from x
+	return d, (x < 0) == (y < 0) || (d < 0) == (x < 0)
+}
+
+func (int64Arithmetic) mulChecked(x, y int64) (int64, bool) {
+	if x == 0 || y == 0 {
+		return 0, true
+	}
+	p := x * y
+	// math.MinInt64 * -1 wraps to math.MinInt64, which passes the division check
+	return p, p/y == x && !(x == math.MinInt64 && y == -1)
+}
+
+func (int64Arithmetic) quoChecked(x, y int64) (int64, bool) {
+	return x / y, x != math.MinInt64 || y != -1
+}
+
+// bigIntArithmetic implements the arbitrary precision integer arithmetic
+type bigIntArithmetic struct{}
+
+func (bigIntArithmetic) parse(s string) (*big.Int, error) {
+	x, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, ErrNonInteger
+	}
+	return x, nil
+}
+
+func (bigIntArithmetic) format(x *big.Int) string {
+	return x.String()
+}
+
+func (bigIntArithmetic) zero() *big.Int             { return new(big.Int) }
+func (bigIntArithmetic) one() *big.Int              { return big.NewInt(1) }
+func (bigIntArithmetic) add(x, y *big.Int) *big.Int { return new(big.Int).Add(x, y) }
+func (bigIntArithmetic) sub(x, y *big.Int) *big.Int { return new(big.Int).Sub(x, y) }
+func (bigIntArithmetic) mul(x, y *big.Int) *big.Int { return new(big.Int).Mul(x, y) }
+func (bigIntArithmetic) quo(x, y *big.Int) *big.Int { return new(big.Int).Quo(x, y) }
+func (bigIntArithmetic) field() bool                { return false }
+func (bigIntArithmetic) isZero(x *big.Int) bool     { return x.Sign() == 0 }
+func (bigIntArithmetic) cmpAbs(x, y *big.Int) int   { return x.CmpAbs(y) }
+
+// complexArithmetic implements the complex128 arithmetic, the elements are written like 1+2i
+type complexArithmetic struct{}
+
+func (complexArithmetic) parse(s string) (complex128, error) {
+	x, err := strconv.ParseComplex(s, 128)
+	if err != nil {
+		return 0, ErrNonComplex
+	}
+	return x, nil
+}
+
+// format writes the complex number without parentheses, e.g. 1+2i, and the real number as is, e.g. 1.5
+func (complexArithmetic) format(x complex128) string {
+	if imag(x) == 0 {
+		return strconv.FormatFloat(real(x), 'g', -1, 64)
+	}
+	return strings.Trim(strconv.FormatComplex(x, 'g', -1, 128), "()")
+}
+
+func (complexArithmetic) zero() complex128               { return 0 }
+func (complexArithmetic) one() complex128                { return 1 }
+func (complexArithmetic) add(x, y complex128) complex128 { return x + y }
+func (complexArithmetic) sub(x, y complex128) complex128 { return x - y }
+func (complexArithmetic) mul(x, y complex128) complex128 { return x * y }
+func (complexArithmetic) quo(x, y complex128) complex128 { return x / y }
+func (complexArithmetic) field() bool                    { return true }
+func (complexArithmetic) isZero(x complex128) bool       { return x == 0 }
+func (complexArithmetic) cmpAbs(x, y complex128) int {
+	return floatArithmetic{}.cmpAbs(cmplx.Abs(x), cmplx.Abs(y))
+}
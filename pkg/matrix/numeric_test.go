@@ -0,0 +1,125 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func Test_decimalArithmetic_format(t *testing.T) {
+	type args struct {
+		x *big.Rat
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want string
+	}{
+		{
+			name: "decimal format happy path with integer",
+			when: "the number is integer",
+			then: "the number should be formatted without fractional part",
+
+			args: args{x: big.NewRat(-42, 1)},
+			want: "-42",
+		},
+		{
+			name: "decimal format happy path with terminating decimal",
+			when: "the denominator consists of 2 and 5 factors only",
+			then: "the exact decimal should be returned",
+
+			args: args{x: big.NewRat(3, 40)},
+			want: "0.075",
+		},
+		{
+			name: "decimal format happy path with non-terminating decimal",
+			when: "the denominator has other prime factors",
+			then: "the decimal rounded to the max scale should be returned",
+
+			args: args{x: big.NewRat(2, 3)},
+			want: "0.66666666666666666667",
+		},
+		{
+			name: "decimal format happy path with rounded trailing zeros",
+			when: "the rounded decimal ends with zeros",
+			then: "the trailing zeros should be trimmed",
+
+			args: args{x: big.NewRat(1, 3*10000000000000000)},
+			want: "0.00000000000000003333",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (decimalArithmetic{}).format(tt.args.x); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseType(t *testing.T) {
+	type args struct {
+		s string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args         args
+		wantQuotient string
+		wantErr      error
+	}{
+		{
+			name: "parse type happy path",
+			when: "the int type is requested",
+			then: "the type with exact fractions should be returned",
+
+			args:         args{s: "int"},
+			wantQuotient: "3/2",
+		},
+		{
+			name: "parse type happy path with float type",
+			when: "the float type is requested",
+			then: "the float type should be returned",
+
+			args:         args{s: "float"},
+			wantQuotient: "1.5",
+		},
+		{
+			name: "parse type unhappy path",
+			when: "the unknown type is requested",
+			then: "error should be returned",
+
+			args:    args{s: "quaternion"},
+			wantErr: ErrUnknownType,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseType(tt.args.s)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			// the types are told apart by the way they divide
+			quotient, err := mustNew([][]string{{"3"}}).Div(mustNew([][]string{{"2"}}), got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quotient.At(0, 0) != tt.wantQuotient {
+				t.Errorf(errTemplate, meta, quotient.At(0, 0), tt.wantQuotient)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package matrix
+
+import (
+	"errors"
+	"io"
+)
+
+// sumRows gets the sum of matrix elements, the rows are read one by one without holding the whole matrix
+func sumRows[T Number](ar arithmetic[T], rows RowReader) (T, error) {
+	return foldRows(ar, rows, ar.zero(), (*calculation[T]).add)
+}
+
+// multiplyRows gets the product of matrix elements, the rows are read one by one without holding the whole matrix
+func multiplyRows[T Number](ar arithmetic[T], rows RowReader) (T, error) {
+	return foldRows(ar, rows, ar.one(), (*calculation[T]).mul) // in case of multiplying the initial value should be 1
+}
+
+// foldRows accumulates all matrix elements with op, starting with initial.
+// The invalid elements don't stop the reading, so all of them are reported at once
+func foldRows[T Number](ar arithmetic[T], rows RowReader, initial T, op func(calc *calculation[T], x, y T) T) (T, error) {
+	calc := newCalculation(ar)
+	total := initial
+	var errs CellErrors
+	for i := 0; ; i++ {
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			if err := errs.Err(); err != nil {
+				return total, err
+			}
+			return total, calc.err
+		}
+		if err != nil {
+			return total, err
+		}
+		for j := range row {
+			elem, err := ar.parse(row[j])
+			if err != nil {
+				errs.Add(&CellError{Err: err, Row: i + 1, Col: j + 1, Value: row[j]})
+				continue
+			}
+			total = op(calc, total, elem)
+		}
+	}
+}
+
+// parseMatrix converts the matrix elements with ar, all invalid elements are reported at once
+func parseMatrix[T Number](ar arithmetic[T], t *Text) (*Matrix[T], error) {
+	var errs CellErrors
+	m := parseText(ar, t, "", &errs)
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseOperands converts both operands of the binary operation, the invalid elements of both are reported at once
+func parseOperands[T Number](ar arithmetic[T], a, b *Text) (matrixA, matrixB *Matrix[T], err error) {
+	var errs CellErrors
+	matrixA = parseText(ar, a, LeftOperand, &errs)
+	matrixB = parseText(ar, b, RightOperand, &errs)
+	if err := errs.Err(); err != nil {
+		return nil, nil, err
+	}
+	return matrixA, matrixB, nil
+}
+
+// parseText converts the elements of the text matrix, the invalid ones are added to errs
+func parseText[T Number](ar arithmetic[T], t *Text, operand string, errs *CellErrors) *Matrix[T] {
+	m := newMatrix(ar, t.rows, t.cols)
+	for i, cell := range t.cells {
+		elem, err := ar.parse(cell)
+		if err != nil {
+			errs.Add(&CellError{Err: err, Row: i/t.cols + 1, Col: i%t.cols + 1, Operand: operand, Value: cell})
+		}
+		m.elems = append(m.elems, elem)
+	}
+	return m
+}
+
+// parseSparseMatrix converts the stored elements of the sparse matrix with ar, all invalid elements are reported at once
+func parseSparseMatrix[T Number](ar arithmetic[T], t *SparseText) (*Sparse[T], error) {
+	var errs CellErrors
+	m := parseSparseText(ar, t, "", &errs)
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseSparseOperands converts both sparse operands of the binary operation, the invalid elements of both are reported at once
+func parseSparseOperands[T Number](ar arithmetic[T], a, b *SparseText) (matrixA, matrixB *Sparse[T], err error) {
+	var errs CellErrors
+	matrixA = parseSparseText(ar, a, LeftOperand, &errs)
+	matrixB = parseSparseText(ar, b, RightOperand, &errs)
+	if err := errs.Err(); err != nil {
+		return nil, nil, err
+	}
+	return matrixA, matrixB, nil
+}
+
+// parseSparseText converts the stored elements of the sparse text matrix, the invalid ones are added to errs.
+// The elements, which are parsed as zeros, are dropped
+func parseSparseText[T Number](ar arithmetic[T], t *SparseText, operand string, errs *CellErrors) *Sparse[T] {
+	m := &Sparse[T]{csr: newEmptyCSR[T](t.rows, t.cols, len(t.elems)), ar: ar}
+	for i := 0; i < t.rows; i++ {
+		for k := t.rowStart[i]; k < t.rowStart[i+1]; k++ {
+			elem, err := ar.parse(t.elems[k])
+			if err != nil {
+				errs.Add(&CellError{Err: err, Row: i + 1, Col: t.colIndex[k] + 1, Operand: operand, Value: t.elems[k]})
+				continue
+			}
+			if !ar.isZero(elem) {
+				m.colIndex = append(m.colIndex, t.colIndex[k])
+				m.elems = append(m.elems, elem)
+			}
+		}
+		m.rowStart[i+1] = len(m.elems)
+	}
+	return m
+}
@@ -0,0 +1,242 @@
+package matrix
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrUnknownStyle = errors.New("unknown style, table, markdown, latex and html allowed")
+
+// Style is the human-readable rendering of the matrix, see Encoder
+type Style string
+
+const (
+	TableStyle    Style = "table"    // plain text with aligned columns
+	MarkdownStyle Style = "markdown" // GitHub flavored Markdown table
+	LaTeXStyle    Style = "latex"    // tabular environment
+	HTMLStyle     Style = "html"     // table element
+)
+
+// Encoder writes the matrices as tables, so they can be pasted into tickets and papers.
+// The numeric columns are right-aligned and the rest are left-aligned
+type Encoder struct {
+	w       io.Writer
+	style   Style
+	indices bool
+}
+
+// NewEncoder returns the encoder of the style writing to w
+func NewEncoder(w io.Writer, style Style) *Encoder {
+	return &Encoder{w: w, style: style}
+}
+
+// SetIndices labels the rows and columns with their 1-based indices
+func (e *Encoder) SetIndices(indices bool) {
+	e.indices = indices
+}
+
+// Encode writes the matrix as the table of the encoder style
+func (e *Encoder) Encode(m *Text) error {
+	out := bufio.NewWriter(e.w)
+	switch e.style {
+	case TableStyle:
+		e.encodeTable(out, m)
+	case MarkdownStyle:
+		e.encodeMarkdown(out, m)
+	case LaTeXStyle:
+		e.encodeLaTeX(out, m)
+	case HTMLStyle:
+		e.encodeHTML(out, m)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownStyle, e.style)
+	}
+	return out.Flush()
+}
+
+// layout is the matrix with the optional index labels: the header row of the column indices
+// and the first column of the row indices
+type layout struct {
+	header []string // nil without indices
+	rows   [][]string
+	right  []bool // right alignment of each column, including the one of the row indices
+	widths []int  // widths of each column in runes
+}
+
+// newLayout escapes the elements and measures the columns, minWidth is the smallest width of the column
+func (e *Encoder) newLayout(m *Text, escape func(string) string, minWidth int) layout {
+	l := layout{rows: make([][]string, m.rows)}
+	for i := range l.rows {
+		l.rows[i] = make([]string, 0, m.cols+1)
+		if e.indices {
+			l.rows[i] = append(l.rows[i], strconv.Itoa(i+1))
+		}
+		for j := 0; j < m.cols; j++ {
+			l.rows[i] = append(l.rows[i], escape(m.At(i, j)))
+		}
+	}
+	if e.indices {
+		l.header = make([]string, 0, m.cols+1)
+		l.header = append(l.header, "")
+		for j := 0; j < m.cols; j++ {
+			l.header = append(l.header, strconv.Itoa(j+1))
+		}
+		l.right = append(l.right, true)
+	}
+	for j := 0; j < m.cols; j++ {
+		numeric := true
+		for i := 0; i < m.rows && numeric; i++ {
+			numeric = isNumeric(m.At(i, j))
+		}
+		l.right = append(l.right, numeric)
+	}
+
+	lines := l.rows
+	if l.header != nil {
+		lines = append([][]string{l.header}, l.rows...)
+	}
+	l.widths = make([]int, len(l.right))
+	for j := range l.widths {
+		l.widths[j] = minWidth
+		for _, line := range lines {
+			if width := utf8.RuneCountInString(line[j]); width > l.widths[j] {
+				l.widths[j] = width
+			}
+		}
+	}
+	return l
+}
+
+// pad aligns the cells of the row in their columns
+func (l layout) pad(row []string) []string {
+	padded := make([]string, len(row))
+	for j, cell := range row {
+		padding := strings.Repeat(" ", l.widths[j]-utf8.RuneCountInString(cell))
+		if l.right[j] {
+			padded[j] = padding + cell
+		} else {
+			padded[j] = cell + padding
+		}
+	}
+	return padded
+}
+
+func (e *Encoder) encodeTable(out *bufio.Writer, m *Text) {
+	l := e.newLayout(m, func(s string) string { return s }, 0)
+	writeLine := func(row []string) {
+		out.WriteString(strings.TrimRight(strings.Join(l.pad(row), "  "), " "))
+		out.WriteString("\n")
+	}
+	if l.header != nil {
+		writeLine(l.header)
+	}
+	for _, row := range l.rows {
+		writeLine(row)
+	}
+}
+
+// encodeMarkdown writes the table with the header of the column indices, the header is empty without indices,
+// as Markdown tables can't go without it
+func (e *Encoder) encodeMarkdown(out *bufio.Writer, m *Text) {
+	l := e.newLayout(m, func(s string) string { return strings.ReplaceAll(s, "|", `\|`) }, 3)
+	header := l.header
+	if header == nil {
+		header = make([]string, len(l.widths))
+	}
+	writeLine := func(row []string) {
+		out.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+	writeLine(l.pad(header))
+	delimiters := make([]string, len(l.widths))
+	for j, width := range l.widths {
+		delimiters[j] = strings.Repeat("-", width)
+		if l.right[j] {
+			delimiters[j] = strings.Repeat("-", width-1) + ":"
+		}
+	}
+	writeLine(delimiters)
+	for _, row := range l.rows {
+		writeLine(l.pad(row))
+	}
+}
+
+var latexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, "&", `\&`, "%", `\%`, "$", `\$`, "#", `\#`, "_", `\_`,
+	"{", `\{`, "}", `\}`, "~", `\textasciitilde{}`, "^", `\textasciicircum{}`,
+)
+
+// encodeLaTeX writes the tabular environment, the indices are separated from the elements with the rules
+func (e *Encoder) encodeLaTeX(out *bufio.Writer, m *Text) {
+	l := e.newLayout(m, latexEscaper.Replace, 0)
+	var spec strings.Builder
+	for j, right := range l.right {
+		if right {
+			spec.WriteString("r")
+		} else {
+			spec.WriteString("l")
+		}
+		if j == 0 && e.indices {
+			spec.WriteString("|")
+		}
+	}
+	writeLine := func(row []string) {
+		out.WriteString(strings.Join(l.pad(row), " & ") + ` \\` + "\n")
+	}
+
+	out.WriteString(`\begin{tabular}{` + spec.String() + "}\n")
+	if l.header != nil {
+		writeLine(l.header)
+		out.WriteString(`\hline` + "\n")
+	}
+	for _, row := range l.rows {
+		writeLine(row)
+	}
+	out.WriteString(`\end{tabular}` + "\n")
+}
+
+// encodeHTML writes the table element, the indices are th elements of the header row and of the first column
+func (e *Encoder) encodeHTML(out *bufio.Writer, m *Text) {
+	l := e.newLayout(m, html.EscapeString, 0)
+	out.WriteString("<table>\n")
+	if l.header != nil {
+		out.WriteString("  <thead>\n    <tr>")
+		for _, cell := range l.header {
+			out.WriteString("<th>" + cell + "</th>")
+		}
+		out.WriteString("</tr>\n  </thead>\n")
+	}
+	out.WriteString("  <tbody>\n")
+	for _, row := range l.rows {
+		out.WriteString("    <tr>")
+		for j, cell := range row {
+			switch {
+			case j == 0 && e.indices:
+				out.WriteString("<th>" + cell + "</th>")
+			case l.right[j]:
+				out.WriteString(`<td style="text-align: right">` + cell + "</td>")
+			default:
+				out.WriteString("<td>" + cell + "</td>")
+			}
+		}
+		out.WriteString("</tr>\n")
+	}
+	out.WriteString("  </tbody>\n</table>\n")
+}
+
+// isNumeric checks if the element is a number of any supported type, e.g. 12, -0.5, 1/3 or 1+2i
+func isNumeric(s string) bool {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if _, ok := new(big.Rat).SetString(s); ok {
+		return true
+	}
+	_, err := strconv.ParseComplex(s, 128)
+	return err == nil
+}
@@ -0,0 +1,125 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEncoder_Encode(t *testing.T) {
+	mixed := [][]string{{"1", "x|y", "-2.5"}, {"100", "a_b", "1/3"}}
+	type args struct {
+		records [][]string
+		style   Style
+		indices bool
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "encode table happy path",
+			when: "the matrix has numeric and text columns",
+			then: "the numeric columns should be right-aligned and the text ones left-aligned",
+
+			args: args{records: mixed, style: TableStyle},
+			want: "  1  x|y  -2.5\n100  a_b   1/3\n",
+		},
+		{
+			name: "encode table happy path with indices",
+			when: "the indices are requested",
+			then: "the rows and columns should be labeled with 1-based indices",
+
+			args: args{records: mixed, style: TableStyle, indices: true},
+			want: "     1  2       3\n1    1  x|y  -2.5\n2  100  a_b   1/3\n",
+		},
+		{
+			name: "encode Markdown happy path",
+			when: "the indices aren't requested",
+			then: "the header should be empty and the pipes should be escaped",
+
+			args: args{records: mixed, style: MarkdownStyle},
+			want: "|     |      |      |\n" +
+				"| --: | ---- | ---: |\n" +
+				"|   1 | x\\|y | -2.5 |\n" +
+				"| 100 | a_b  |  1/3 |\n",
+		},
+		{
+			name: "encode Markdown happy path with indices",
+			when: "the indices are requested",
+			then: "the header should have the column indices",
+
+			args: args{records: [][]string{{"1", "2"}}, style: MarkdownStyle, indices: true},
+			want: "|     |   1 |   2 |\n" +
+				"| --: | --: | --: |\n" +
+				"|   1 |   1 |   2 |\n",
+		},
+		{
+			name: "encode LaTeX happy path with indices",
+			when: "the matrix has special characters and the indices are requested",
+			then: "the tabular should have the rules after the indices and the characters should be escaped",
+
+			args: args{records: mixed, style: LaTeXStyle, indices: true},
+			want: "\\begin{tabular}{r|rlr}\n" +
+				"  &   1 & 2    &    3 \\\\\n" +
+				"\\hline\n" +
+				"1 &   1 & x|y  & -2.5 \\\\\n" +
+				"2 & 100 & a\\_b &  1/3 \\\\\n" +
+				"\\end{tabular}\n",
+		},
+		{
+			name: "encode HTML happy path",
+			when: "the matrix has the markup",
+			then: "the markup should be escaped and the numbers right-aligned",
+
+			args: args{records: [][]string{{"<b>", "2"}}, style: HTMLStyle},
+			want: "<table>\n  <tbody>\n" +
+				"    <tr><td>&lt;b&gt;</td><td style=\"text-align: right\">2</td></tr>\n" +
+				"  </tbody>\n</table>\n",
+		},
+		{
+			name: "encode HTML happy path with indices",
+			when: "the indices are requested",
+			then: "the indices should be header cells",
+
+			args: args{records: [][]string{{"1+2i"}}, style: HTMLStyle, indices: true},
+			want: "<table>\n  <thead>\n    <tr><th></th><th>1</th></tr>\n  </thead>\n  <tbody>\n" +
+				"    <tr><th>1</th><td style=\"text-align: right\">1+2i</td></tr>\n" +
+				"  </tbody>\n</table>\n",
+		},
+		{
+			name: "encode unhappy path with unknown style",
+			when: "the style doesn't exist",
+			then: "error should be returned",
+
+			args:    args{records: mixed, style: "rst"},
+			wantErr: ErrUnknownStyle,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewText(tt.args.records)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			encoder := NewEncoder(&got, tt.args.style)
+			encoder.SetIndices(tt.args.indices)
+			err = encoder.Encode(m)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf(errTemplate, meta, err, tt.wantErr)
+			}
+			if got.String() != tt.want {
+				t.Errorf(errTemplate, meta, got.String(), tt.want)
+			}
+		})
+	}
+}
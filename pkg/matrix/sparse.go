@@ -0,0 +1,221 @@
+package matrix
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Sparse is the non-empty numeric matrix, which stores only the non-zero elements in the compressed sparse row form.
+// Transpose, Sum, Product, Add, Sub and Mul take time proportional to the number of non-zero elements rather than
+// to the shape. It's never modified after construction
+type Sparse[T Number] struct {
+	csr[T]
+	ar arithmetic[T]
+}
+
+// NewSparse makes the rows x cols matrix of the entries, the elements not listed are zeros.
+// The entries at the same position are summed and the zero ones are dropped
+func NewSparse[T Number](rows, cols int, entries []Entry[T]) (*Sparse[T], error) {
+	ar := newCalculation(arithmeticOf[T]())
+	m, err := newCSR(rows, cols, entries, ar.add)
+	if err != nil {
+		return nil, err
+	}
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	return (&Sparse[T]{csr: m, ar: ar.arithmetic}).dropZeros(), nil
+}
+
+// Sparse converts the matrix to the sparse one, the zero elements are dropped
+func (m *Matrix[T]) Sparse() *Sparse[T] {
+	s := &Sparse[T]{csr: newEmptyCSR[T](m.rows, m.cols, 0), ar: m.ar}
+	for i, elem := range m.elems {
+		if !m.ar.isZero(elem) {
+			s.colIndex = append(s.colIndex, i%m.cols)
+			s.elems = append(s.elems, elem)
+		}
+		s.rowStart[i/m.cols+1] = len(s.elems)
+	}
+	return s
+}
+
+// At gets the element at row i and column j, both 0-based
+func (m *Sparse[T]) At(i, j int) T {
+	if elem, ok := m.find(i, j); ok {
+		return elem
+	}
+	return m.ar.zero()
+}
+
+// Dense converts the matrix to the one storing all elements
+func (m *Sparse[T]) Dense() *Matrix[T] {
+	dense := newMatrix(m.ar, m.rows, m.cols)
+	for i := 0; i < m.rows*m.cols; i++ {
+		dense.elems = append(dense.elems, m.ar.zero())
+	}
+	for i := 0; i < m.rows; i++ {
+		for k := m.rowStart[i]; k < m.rowStart[i+1]; k++ {
+			dense.elems[i*m.cols+m.colIndex[k]] = m.elems[k]
+		}
+	}
+	return dense
+}
+
+// Text formats the non-zero elements of the matrix
+func (m *Sparse[T]) Text() *SparseText {
+	t := &SparseText{csr: csr[string]{
+		rows:     m.rows,
+		cols:     m.cols,
+		rowStart: m.rowStart,
+		colIndex: m.colIndex,
+		elems:    make([]string, len(m.elems)),
+	}}
+	for k, elem := range m.elems {
+		t.elems[k] = m.ar.format(elem)
+	}
+	return t
+}
+
+// String represents the matrix as CSV triplets, see SparseText.String
+func (m *Sparse[T]) String() string {
+	return m.Text().String()
+}
+
+// Transpose swaps the rows and columns of the matrix
+func (m *Sparse[T]) Transpose() *Sparse[T] {
+	return &Sparse[T]{csr: m.transpose(), ar: m.ar}
+}
+
+// Sum gets the sum of the elements
+func (m *Sparse[T]) Sum() (T, error) {
+	ar := newCalculation(m.ar)
+	sum := ar.zero()
+	for _, elem := range m.elems {
+		sum = ar.add(sum, elem)
+	}
+	return sum, ar.err
+}
+
+// Product gets the product of the elements, which is zero when any element isn't stored
+func (m *Sparse[T]) Product() (T, error) {
+	ar := newCalculation(m.ar)
+	if len(m.elems) < m.rows*m.cols {
+		return ar.zero(), nil
+	}
+	product := ar.one()
+	for _, elem := range m.elems {
+		product = ar.mul(product, elem)
+	}
+	return product, ar.err
+}
+
+// Add computes the element-wise sum m+b of matrices of the same shape
+func (m *Sparse[T]) Add(b *Sparse[T]) (*Sparse[T], error) {
+	return m.merge(b, (*calculation[T]).add)
+}
+
+// Sub computes the element-wise difference m-b of matrices of the same shape
+func (m *Sparse[T]) Sub(b *Sparse[T]) (*Sparse[T], error) {
+	return m.merge(b, (*calculation[T]).sub)
+}
+
+// Mul computes the matrix product m·b row by row with the Gustavson algorithm, so only the products
+// of the non-zero elements are computed. The number of columns of m should match the number of rows of b
+func (m *Sparse[T]) Mul(b *Sparse[T]) (*Sparse[T], error) {
+	if m.cols != b.rows {
+		return nil, fmt.Errorf("%w: can't multiply %s matrix by %s matrix, columns of the first should match rows of the second",
+			ErrDimensionMismatch, m.Shape(), b.Shape())
+	}
+	ar := newCalculation(m.ar)
+	product := &Sparse[T]{csr: newEmptyCSR[T](m.rows, b.cols, 0), ar: m.ar}
+
+	// the accumulator of the current row, touched[j] is the last row which has column j set
+	acc := make([]T, b.cols)
+	touched := make([]int, b.cols)
+	for j := range touched {
+		touched[j] = -1
+	}
+	var cols []int
+	for i := 0; i < m.rows; i++ {
+		cols = cols[:0]
+		for k := m.rowStart[i]; k < m.rowStart[i+1]; k++ {
+			x, row := m.elems[k], m.colIndex[k]
+			for kb := b.rowStart[row]; kb < b.rowStart[row+1]; kb++ {
+				j := b.colIndex[kb]
+				if touched[j] != i {
+					touched[j] = i
+					acc[j] = ar.zero()
+					cols = append(cols, j)
+				}
+				acc[j] = ar.add(acc[j], ar.mul(x, b.elems[kb]))
+			}
+		}
+		sort.Ints(cols)
+		for _, j := range cols {
+			if !ar.isZero(acc[j]) {
+				product.colIndex = append(product.colIndex, j)
+				product.elems = append(product.elems, acc[j])
+			}
+		}
+		product.rowStart[i+1] = len(product.elems)
+	}
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	return product, nil
+}
+
+// merge applies op to each pair of elements of matrices of the same shape, where at least one of them is stored.
+// The missing element is passed as zero, the zero results are dropped
+func (m *Sparse[T]) merge(b *Sparse[T], op func(ar *calculation[T], x, y T) T) (*Sparse[T], error) {
+	if m.rows != b.rows || m.cols != b.cols {
+		return nil, fmt.Errorf("%w: can't combine %s matrix with %s matrix element-wise, shapes should be equal",
+			ErrDimensionMismatch, m.Shape(), b.Shape())
+	}
+	ar := newCalculation(m.ar)
+	merged := &Sparse[T]{csr: newEmptyCSR[T](m.rows, m.cols, len(m.elems)+len(b.elems)), ar: m.ar}
+	appendElem := func(j int, elem T) {
+		if !ar.isZero(elem) {
+			merged.colIndex = append(merged.colIndex, j)
+			merged.elems = append(merged.elems, elem)
+		}
+	}
+	for i := 0; i < m.rows; i++ {
+		k, kb := m.rowStart[i], b.rowStart[i]
+		for k < m.rowStart[i+1] || kb < b.rowStart[i+1] {
+			switch {
+			case kb == b.rowStart[i+1] || k < m.rowStart[i+1] && m.colIndex[k] < b.colIndex[kb]:
+				appendElem(m.colIndex[k], op(ar, m.elems[k], ar.zero()))
+				k++
+			case k == m.rowStart[i+1] || b.colIndex[kb] < m.colIndex[k]:
+				appendElem(b.colIndex[kb], op(ar, ar.zero(), b.elems[kb]))
+				kb++
+			default:
+				appendElem(m.colIndex[k], op(ar, m.elems[k], b.elems[kb]))
+				k++
+				kb++
+			}
+		}
+		merged.rowStart[i+1] = len(merged.elems)
+	}
+	if ar.err != nil {
+		return nil, ar.err
+	}
+	return merged, nil
+}
+
+// dropZeros removes the zero elements, which can be listed in the entries or left after summing the duplicates
+func (m *Sparse[T]) dropZeros() *Sparse[T] {
+	compact := &Sparse[T]{csr: newEmptyCSR[T](m.rows, m.cols, len(m.elems)), ar: m.ar}
+	for i := 0; i < m.rows; i++ {
+		for k := m.rowStart[i]; k < m.rowStart[i+1]; k++ {
+			if !m.ar.isZero(m.elems[k]) {
+				compact.colIndex = append(compact.colIndex, m.colIndex[k])
+				compact.elems = append(compact.elems, m.elems[k])
+			}
+		}
+		compact.rowStart[i+1] = len(compact.elems)
+	}
+	return compact
+}
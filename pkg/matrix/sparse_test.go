@@ -0,0 +1,245 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestNewSparseText(t *testing.T) {
+	type args struct {
+		rows, cols int
+		entries    []Entry[string]
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "new sparse text happy path",
+			when: "the entries are listed in any order",
+			then: "the entries should be stored row by row",
+
+			args: args{rows: 2, cols: 3, entries: []Entry[string]{{Row: 1, Col: 0, Value: "4"}, {Row: 0, Col: 2, Value: "x"}}},
+			want: "2,3\n1,3,x\n2,1,4\n",
+		},
+		{
+			name: "new sparse text happy path without entries",
+			when: "no entries are listed",
+			then: "the zero matrix should be returned",
+
+			args: args{rows: 100000, cols: 100000},
+			want: "100000,100000\n",
+		},
+		{
+			name: "new sparse text unhappy path with empty shape",
+			when: "the matrix has no rows",
+			then: "error should be returned",
+
+			args:    args{rows: 0, cols: 3},
+			wantErr: ErrEmpty,
+		},
+		{
+			name: "new sparse text unhappy path with entry out of range",
+			when: "the entry is out of the matrix",
+			then: "error should be returned",
+
+			args:    args{rows: 2, cols: 2, entries: []Entry[string]{{Row: 2, Col: 0, Value: "1"}}},
+			wantErr: ErrOutOfRange,
+		},
+		{
+			name: "new sparse text unhappy path with duplicated entry",
+			when: "two entries have the same position",
+			then: "error should be returned",
+
+			args:    args{rows: 2, cols: 2, entries: []Entry[string]{{Row: 1, Col: 1, Value: "1"}, {Row: 1, Col: 1, Value: "2"}}},
+			wantErr: ErrDuplicateEntry,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSparseText(tt.args.rows, tt.args.cols, tt.args.entries)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != tt.want {
+				t.Errorf(errTemplate, meta, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSparseText_operations(t *testing.T) {
+	identity := mustNewSparseText(3, 3, "1", "", "", "", "1", "", "", "", "1")
+	band := mustNewSparseText(3, 3, "2", "-1", "", "-1", "2", "-1", "", "-1", "2")
+	column := mustNewSparseText(3, 1, "1.5", "", "x")
+	huge := mustNewSparseText(100000, 100000)
+
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		run     func() (any, error)
+		want    string
+		wantErr error
+	}{
+		{
+			name: "transpose happy path",
+			when: "the column is transposed",
+			then: "the row with the same elements should be returned",
+
+			run:  func() (any, error) { return column.Transpose(), nil },
+			want: "1,3\n1,1,1.5\n1,3,x\n",
+		},
+		{
+			name: "sum happy path",
+			when: "the band matrix is summed",
+			then: "the sum of the stored elements should be returned",
+
+			run:  func() (any, error) { return band.Sum(Int) },
+			want: "2",
+		},
+		{
+			name: "product happy path with zeros",
+			when: "some elements aren't stored",
+			then: "zero should be returned",
+
+			run:  func() (any, error) { return band.Product(BigInt) },
+			want: "0",
+		},
+		{
+			name: "sum unhappy path",
+			when: "the stored element isn't a number",
+			then: "error with the element should be returned",
+
+			run:     func() (any, error) { return column.Sum(Float) },
+			wantErr: ErrNonFloat,
+		},
+		{
+			name: "add happy path with cancelled elements",
+			when: "the sum of the elements is zero",
+			then: "the zero elements shouldn't be stored",
+
+			run: func() (any, error) {
+				return band.Add(mustNewSparseText(3, 3, "", "1", "", "1", "", "1", "", "1", ""), Int)
+			},
+			want: "3,3\n1,1,2\n2,2,2\n3,3,2\n",
+		},
+		{
+			name: "subtract happy path",
+			when: "the identity is subtracted",
+			then: "the diagonal should be decreased",
+
+			run:  func() (any, error) { return band.Sub(identity, Int64) },
+			want: "3,3\n1,1,1\n1,2,-1\n2,1,-1\n2,2,1\n2,3,-1\n3,2,-1\n3,3,1\n",
+		},
+		{
+			name: "add unhappy path with int64 overflow",
+			when: "the sum of the stored elements doesn't fit into int64",
+			then: "error should be returned instead of the wrapped sum",
+
+			run: func() (any, error) {
+				return mustNewSparseText(1, 1, "9223372036854775807").Add(mustNewSparseText(1, 1, "1"), Int64)
+			},
+			wantErr: ErrOverflow,
+		},
+		{
+			name: "multiply happy path",
+			when: "the band matrix is squared",
+			then: "the product of the stored elements should be returned",
+
+			run:  func() (any, error) { return band.Mul(band, Int) },
+			want: "3,3\n1,1,5\n1,2,-4\n1,3,1\n2,1,-4\n2,2,6\n2,3,-4\n3,1,1\n3,2,-4\n3,3,5\n",
+		},
+		{
+			name: "multiply happy path with huge matrix",
+			when: "the shape of the matrix is too large to store all elements",
+			then: "the product should be computed without them",
+
+			run:  func() (any, error) { return huge.Mul(huge.Transpose(), Float) },
+			want: "100000,100000\n",
+		},
+		{
+			name: "multiply unhappy path",
+			when: "the columns of the first matrix don't match the rows of the second",
+			then: "error should be returned",
+
+			run:     func() (any, error) { return band.Mul(mustNewSparseText(2, 2, "1", "", "", "1"), Int) },
+			wantErr: ErrDimensionMismatch,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.run()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if formatted := fmt.Sprint(got); formatted != tt.want {
+				t.Errorf(errTemplate, meta, formatted, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparse_Dense(t *testing.T) {
+	dense := mustNewOf([][]*big.Rat{{big.NewRat(1, 2), new(big.Rat)}, {new(big.Rat), big.NewRat(-3, 1)}})
+	sparse := dense.Sparse()
+	if sparse.NonZeros() != 2 {
+		t.Errorf("unexpected number of non-zero elements: got %d want 2", sparse.NonZeros())
+	}
+	if got, want := sparse.Transpose().Dense().String(), dense.Transpose().String(); got != want {
+		t.Errorf("unexpected transposed matrix: got %q want %q", got, want)
+	}
+	if got := sparse.At(0, 1).String(); got != "0/1" {
+		t.Errorf("unexpected element which isn't stored: got %s want 0/1", got)
+	}
+}
+
+func TestText_Sparse(t *testing.T) {
+	dense, err := NewText([][]string{{"0", "0.0"}, {"0x0", "7"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only "0" is zero of every type, the other zeros are kept to be parsed by the operations
+	if got, want := dense.Sparse().String(), "2,2\n1,2,0.0\n2,1,0x0\n2,2,7\n"; got != want {
+		t.Errorf("unexpected sparse matrix: got %q want %q", got, want)
+	}
+}
+
+// mustNewSparseText makes the sparse matrix of the valid test elements listed row by row, the empty ones aren't stored
+func mustNewSparseText(rows, cols int, elems ...string) *SparseText {
+	var entries []Entry[string]
+	for i, elem := range elems {
+		if elem != "" {
+			entries = append(entries, Entry[string]{Row: i / cols, Col: i % cols, Value: elem})
+		}
+	}
+	m, err := NewSparseText(rows, cols, entries)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
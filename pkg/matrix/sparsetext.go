@@ -0,0 +1,155 @@
+package matrix
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// zeroText is the element of SparseText, which isn't stored
+const zeroText = "0"
+
+// SparseText is the non-empty matrix, which stores only the non-zero elements kept as text in the compressed sparse
+// row form. Its arithmetic operations parse the elements as the requested numeric Type, like the ones of Text.
+// It's never modified after construction
+type SparseText struct {
+	csr[string]
+}
+
+// NewSparseText makes the rows x cols matrix of the entries, the elements not listed are zeros.
+// The entries are copied, the ones at the same position are reported as ErrDuplicateEntry
+func NewSparseText(rows, cols int, entries []Entry[string]) (*SparseText, error) {
+	m, err := newCSR(rows, cols, entries, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SparseText{csr: m}, nil
+}
+
+// Sparse converts the matrix to the sparse one, only the "0" elements are dropped. The other zeros, e.g. 0.0 or 0/1,
+// are kept as written, as they are zeros of some types only, and their text is preserved by the structural operations
+func (m *Text) Sparse() *SparseText {
+	s := &SparseText{csr: newEmptyCSR[string](m.rows, m.cols, 0)}
+	for i, cell := range m.cells {
+		if cell != zeroText {
+			s.colIndex = append(s.colIndex, i%m.cols)
+			s.elems = append(s.elems, cell)
+		}
+		s.rowStart[i/m.cols+1] = len(s.elems)
+	}
+	return s
+}
+
+// At gets the element at row i and column j, both 0-based, the elements which aren't stored are "0"
+func (m *SparseText) At(i, j int) string {
+	if elem, ok := m.find(i, j); ok {
+		return elem
+	}
+	return zeroText
+}
+
+// Dense converts the matrix to the one storing all elements
+func (m *SparseText) Dense() *Text {
+	dense := &Text{rows: m.rows, cols: m.cols, cells: make([]string, m.rows*m.cols)}
+	for i := range dense.cells {
+		dense.cells[i] = zeroText
+	}
+	for i := 0; i < m.rows; i++ {
+		for k := m.rowStart[i]; k < m.rowStart[i+1]; k++ {
+			dense.cells[i*m.cols+m.colIndex[k]] = m.elems[k]
+		}
+	}
+	return dense
+}
+
+// String represents the matrix as CSV triplets: the first line is "rows,cols",
+// and each next one is "row,column,value" of the stored element with 1-based row and column
+func (m *SparseText) String() string {
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(m.rows) + "," + strconv.Itoa(m.cols) + "\n")
+	for _, e := range m.Entries() {
+		sb.WriteString(strconv.Itoa(e.Row+1) + "," + strconv.Itoa(e.Col+1) + "," + e.Value + "\n")
+	}
+	return sb.String()
+}
+
+// Transpose swaps the rows and columns of the matrix
+func (m *SparseText) Transpose() *SparseText {
+	return &SparseText{csr: m.transpose()}
+}
+
+// sparseRowReader reads the rows of the sparse matrix with the zeros filled in
+type sparseRowReader struct {
+	m    *SparseText
+	next int
+	row  []string
+}
+
+func (r *sparseRowReader) Read() ([]string, error) {
+	if r.next >= r.m.rows {
+		return nil, io.EOF
+	}
+	if r.row == nil {
+		r.row = make([]string, r.m.cols)
+	}
+	for j := range r.row {
+		r.row[j] = zeroText
+	}
+	for k := r.m.rowStart[r.next]; k < r.m.rowStart[r.next+1]; k++ {
+		r.row[r.m.colIndex[k]] = r.m.elems[k]
+	}
+	r.next++
+	return r.row, nil
+}
+
+// RowReader reads the rows of the matrix with the zeros filled in, so the matrix can be passed to the operations
+// without the sparse implementation. Each row takes time proportional to the number of columns, the returned row
+// is reused by the next Read call
+func (m *SparseText) RowReader() RowReader {
+	return &sparseRowReader{m: m}
+}
+
+// Sum gets the sum of the elements parsed as t
+func (m *SparseText) Sum(t Type) (string, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return "", err
+	}
+	return ops.sparseSum(m)
+}
+
+// Product gets the product of the elements parsed as t
+func (m *SparseText) Product(t Type) (string, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return "", err
+	}
+	return ops.sparseProduct(m)
+}
+
+// Mul gets the matrix product m·b, the number of columns of m should match the number of rows of b
+func (m *SparseText) Mul(b *SparseText, t Type) (*SparseText, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.sparseMatmul(m, b)
+}
+
+// Add gets the element-wise sum m+b of matrices of the same shape
+func (m *SparseText) Add(b *SparseText, t Type) (*SparseText, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.sparseAdd(m, b)
+}
+
+// Sub gets the element-wise difference m-b of matrices of the same shape
+func (m *SparseText) Sub(b *SparseText, t Type) (*SparseText, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.sparseSubtract(m, b)
+}
@@ -0,0 +1,152 @@
+package matrix
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Text is the non-empty rectangular matrix of the elements kept as text, as they were read,
+// so the structural operations like Transpose work with any elements. It's never modified after construction
+type Text struct {
+	rows, cols int
+	cells      []string // row by row
+}
+
+// NewText makes the matrix of the records, which should be non-empty and have the same number of elements in each row.
+// The records are copied, so they can be reused by the caller
+func NewText(records [][]string) (*Text, error) {
+	if len(records) == 0 || len(records[0]) == 0 {
+		return nil, ErrEmpty
+	}
+	m := &Text{rows: len(records), cols: len(records[0]), cells: make([]string, 0, len(records)*len(records[0]))}
+	for i := range records {
+		if len(records[i]) != m.cols {
+			return nil, fmt.Errorf("%w: row %d has %d elements, but row 1 has %d",
+				ErrRagged, i+1, len(records[i]), m.cols)
+		}
+		m.cells = append(m.cells, records[i]...)
+	}
+	return m, nil
+}
+
+// ParseText reads the matrix from CSV. The rows of different length are rejected by the CSV reader itself,
+// with the line of the offending row
+func ParseText(r io.Reader) (*Text, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return NewText(records)
+}
+
+// Rows gets the number of rows
+func (m *Text) Rows() int {
+	return m.rows
+}
+
+// Cols gets the number of columns
+func (m *Text) Cols() int {
+	return m.cols
+}
+
+// At gets the element at row i and column j, both 0-based
+func (m *Text) At(i, j int) string {
+	if i < 0 || i >= m.rows || j < 0 || j >= m.cols {
+		panic(fmt.Sprintf("matrix: index [%d][%d] out of range of %s matrix", i, j, m.Shape()))
+	}
+	return m.cells[i*m.cols+j]
+}
+
+// Row gets the copy of the row i, 0-based
+func (m *Text) Row(i int) []string {
+	return append([]string(nil), m.cells[i*m.cols:(i+1)*m.cols]...)
+}
+
+// Records gets the copy of the elements row by row
+func (m *Text) Records() [][]string {
+	records := make([][]string, m.rows)
+	for i := range records {
+		records[i] = m.Row(i)
+	}
+	return records
+}
+
+// IsSquare checks if the number of rows is equal to the number of columns
+func (m *Text) IsSquare() bool {
+	return m.rows == m.cols
+}
+
+// Shape represents the dimensions of matrix as "rows x columns", e.g. "3x2"
+func (m *Text) Shape() string {
+	return fmt.Sprintf("%dx%d", m.rows, m.cols)
+}
+
+// String represents the matrix as CSV, one line per row
+func (m *Text) String() string {
+	var sb strings.Builder
+	for i := 0; i < m.rows; i++ {
+		sb.WriteString(strings.Join(m.cells[i*m.cols:(i+1)*m.cols], ","))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Transpose swaps the rows and columns of the matrix
+func (m *Text) Transpose() *Text {
+	transposed := &Text{rows: m.cols, cols: m.rows, cells: make([]string, len(m.cells))}
+	for i := 0; i < m.rows; i++ {
+		for j := 0; j < m.cols; j++ {
+			transposed.cells[j*transposed.cols+i] = m.cells[i*m.cols+j]
+		}
+	}
+	return transposed
+}
+
+// Flatten lists the matrix elements row by row
+func (m *Text) Flatten() []string {
+	return append([]string(nil), m.cells...)
+}
+
+// RowReader reads the matrix row by row, io.EOF is returned after the last row.
+// The returned row may be reused by the next Read call, like in csv.Reader with ReuseRecord
+type RowReader interface {
+	Read() ([]string, error)
+}
+
+// rowReader reads the rows of the matrix
+type rowReader struct {
+	m    *Text
+	next int
+}
+
+func (r *rowReader) Read() ([]string, error) {
+	if r.next >= r.m.rows {
+		return nil, io.EOF
+	}
+	row := r.m.cells[r.next*r.m.cols : (r.next+1)*r.m.cols]
+	r.next++
+	return row, nil
+}
+
+// RowReader reads the rows of the matrix, the returned rows must not be modified
+func (m *Text) RowReader() RowReader {
+	return &rowReader{m: m}
+}
+
+// ReadAll reads the rest of the rows into the matrix
+func ReadAll(rows RowReader) (*Text, error) {
+	var records [][]string
+	for {
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			return NewText(records)
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, append([]string(nil), row...))
+	}
+}
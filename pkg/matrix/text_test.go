@@ -0,0 +1,1188 @@
+package matrix
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	metaTemplate = "testData %s #%d; when %s, then %s"
+	errTemplate  = "%s \n got = %v \n want = %v \n"
+)
+
+var (
+	validIntMatrix    = [][]string{{"1", "2", "3"}, {"4", "5", "6"}, {"7", "8", "9"}}
+	matrixWithStrings = [][]string{{"a", "b", "c"}, {"4", "5", "6"}, {"7", "8", "9"}}
+	overflowIntMatrix = [][]string{{"9223372036854775807", "2"}, {"3", "4"}}
+)
+
+func TestNewText(t *testing.T) {
+	type args struct {
+		records [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args      args
+		wantShape string
+		wantErr   error
+	}{
+		{
+			name: "new text matrix happy path",
+			when: "all rows have the same number of elements",
+			then: "the matrix of the records shape should be returned",
+
+			args:      args{records: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			wantShape: "2x3",
+		},
+		{
+			name: "new text matrix unhappy path with empty records",
+			when: "there are no records",
+			then: "error should be returned",
+
+			args:    args{records: nil},
+			wantErr: ErrEmpty,
+		},
+		{
+			name: "new text matrix unhappy path with empty row",
+			when: "the first row has no elements",
+			then: "error should be returned",
+
+			args:    args{records: [][]string{{}}},
+			wantErr: ErrEmpty,
+		},
+		{
+			name: "new text matrix unhappy path with ragged rows",
+			when: "the rows have different number of elements",
+			then: "error should be returned",
+
+			args:    args{records: [][]string{{"1"}, {"2", "3"}}},
+			wantErr: ErrRagged,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewText(tt.args.records)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Shape() != tt.wantShape {
+				t.Errorf(errTemplate, meta, got.Shape(), tt.wantShape)
+			}
+			// the records are copied, so changing them doesn't change the matrix
+			tt.args.records[0][0] = "changed"
+			if got.At(0, 0) == "changed" {
+				t.Errorf(errTemplate, meta, got.At(0, 0), "the copy of the records")
+			}
+		})
+	}
+}
+
+func TestParseText(t *testing.T) {
+	type args struct {
+		csv string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "parse text matrix happy path",
+			when: "the CSV is valid",
+			then: "the matrix should be returned",
+
+			args: args{csv: "1,2\n3,4\n"},
+			want: [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name: "parse text matrix unhappy path with empty CSV",
+			when: "the CSV has no rows",
+			then: "error should be returned",
+
+			args:    args{csv: ""},
+			wantErr: ErrEmpty,
+		},
+		{
+			name: "parse text matrix unhappy path with ragged rows",
+			when: "the CSV rows have different number of fields",
+			then: "the CSV reader error should be returned",
+
+			args:    args{csv: "1,2\n3\n"},
+			wantErr: csv.ErrFieldCount,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseText(strings.NewReader(tt.args.csv))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Transpose(t *testing.T) {
+	type args struct {
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want [][]string
+	}{
+		{
+			name: "transpose matrix happy path",
+			when: "everything is OK",
+			then: "the columns and rows in matrix should be swapped",
+
+			args: args{matrix: validIntMatrix},
+			want: [][]string{{"1", "4", "7"}, {"2", "5", "8"}, {"3", "6", "9"}},
+		},
+		{
+			name: "transpose matrix happy path with rectangular matrix",
+			when: "the matrix is not square",
+			then: "the n×m matrix should become m×n",
+
+			args: args{matrix: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			want: [][]string{{"1", "4"}, {"2", "5"}, {"3", "6"}},
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustNew(tt.args.matrix).Transpose().Records(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Inverse(t *testing.T) {
+	type args struct {
+		typ    Type
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "inverse matrix happy path",
+			when: "the matrix is invertible",
+			then: "the inverse matrix with exact fractions should be returned",
+
+			args: args{matrix: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}},
+			want: [][]string{{"3/4", "1/2", "1/4"}, {"1/2", "1", "1/2"}, {"1/4", "1/2", "3/4"}},
+		},
+		{
+			name: "inverse matrix happy path with row swap",
+			when: "the first pivot is zero",
+			then: "the rows should be swapped and the inverse matrix should be returned",
+
+			args: args{matrix: [][]string{{"0", "1"}, {"1", "0"}}},
+			want: [][]string{{"0", "1"}, {"1", "0"}},
+		},
+		{
+			name: "inverse matrix unhappy path with singular matrix",
+			when: "the determinant of the matrix is zero",
+			then: "error should be returned",
+
+			args:    args{matrix: validIntMatrix},
+			wantErr: ErrSingular,
+		},
+		{
+			name: "inverse matrix unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{matrix: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+		{
+			name: "inverse matrix happy path with decimal type",
+			when: "the inverse has non-terminating decimals",
+			then: "the rounded decimals should be returned",
+
+			args: args{typ: Decimal, matrix: [][]string{{"3", "0"}, {"0", "0.5"}}},
+			want: [][]string{{"0.33333333333333333333", "0"}, {"0", "2"}},
+		},
+		{
+			name: "inverse matrix happy path with float type",
+			when: "the matrix consists floating-point numbers",
+			then: "the float inverse should be returned",
+
+			args: args{typ: Float, matrix: [][]string{{"0.5", "0"}, {"0", "4"}}},
+			want: [][]string{{"2", "0"}, {"0", "0.25"}},
+		},
+		{
+			name: "inverse matrix happy path with complex type",
+			when: "the matrix consists complex numbers",
+			then: "the complex inverse should be returned",
+
+			args: args{typ: Complex, matrix: [][]string{{"2i", "0"}, {"0", "1"}}},
+			want: [][]string{{"0-0.5i", "0"}, {"0", "1"}},
+		},
+		{
+			name: "inverse matrix unhappy path with int64 type",
+			when: "the elements have no exact division",
+			then: "error should be returned",
+
+			args:    args{typ: Int64, matrix: [][]string{{"2", "0"}, {"0", "1"}}},
+			wantErr: ErrUnsupported,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.matrix).Inverse(typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Flatten(t *testing.T) {
+	type args struct {
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want string
+	}{
+		{
+			name: "matrix to flat string happy path",
+			when: "everything is OK",
+			then: "matrix presented as flat string",
+
+			args: args{matrix: validIntMatrix},
+			want: "1,2,3,4,5,6,7,8,9",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(mustNew(tt.args.matrix).Flatten(), ","); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_String(t *testing.T) {
+	type args struct {
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want string
+	}{
+		{
+			name: "matrix to string happy path",
+			when: "everything is OK",
+			then: "matrix presented as flat string",
+
+			args: args{matrix: validIntMatrix},
+			want: "1,2,3\n4,5,6\n7,8,9\n",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustNew(tt.args.matrix).String(); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductRows(t *testing.T) {
+	type args struct {
+		typ    Type
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "matrix multiply happy path",
+			when: "everything is OK",
+			then: "the product of matrix elements should be returned",
+
+			args: args{matrix: validIntMatrix},
+			want: "362880",
+		},
+		{
+			name: "matrix multiply happy path with overflow",
+			when: "the product doesn't fit into int64",
+			then: "the exact product of matrix elements should be returned",
+
+			args: args{matrix: overflowIntMatrix},
+			want: "221360928884514619368",
+		},
+		{
+			name: "matrix to string unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{matrix: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+		{
+			name: "matrix multiply happy path with decimal type",
+			when: "the matrix consists decimals",
+			then: "the exact decimal product should be returned",
+
+			args: args{typ: Decimal, matrix: [][]string{{"1.5", "0.2"}, {"-3", "2.5e-1"}}},
+			want: "-0.225",
+		},
+		{
+			name: "matrix multiply unhappy path with int64 overflow",
+			when: "the product of int64 elements doesn't fit into int64",
+			then: "error should be returned instead of the wrapped product",
+
+			args:    args{typ: Int64, matrix: [][]string{{"-9223372036854775808", "-1"}}},
+			wantErr: ErrOverflow,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProductRows(typeOrInt(tt.args.typ), mustNew(tt.args.matrix).RowReader())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Determinant(t *testing.T) {
+	type args struct {
+		typ    Type
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "determinant happy path",
+			when: "everything is OK",
+			then: "the determinant should be returned",
+
+			args: args{matrix: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}},
+			want: "4",
+		},
+		{
+			name: "determinant happy path with zero pivot",
+			when: "the rows should be swapped during elimination",
+			then: "the determinant with the correct sign should be returned",
+
+			args: args{matrix: [][]string{{"0", "2", "1"}, {"3", "0", "4"}, {"5", "6", "0"}}},
+			want: "58",
+		},
+		{
+			name: "determinant happy path with singular matrix",
+			when: "the matrix is singular",
+			then: "zero should be returned",
+
+			args: args{matrix: validIntMatrix},
+			want: "0",
+		},
+		{
+			name: "determinant happy path with single element",
+			when: "the matrix is 1x1",
+			then: "the element itself should be returned",
+
+			args: args{matrix: [][]string{{"-7"}}},
+			want: "-7",
+		},
+		{
+			name: "determinant happy path with overflow",
+			when: "the determinant doesn't fit into int64",
+			then: "the exact determinant should be returned",
+
+			args: args{matrix: [][]string{{"9223372036854775807", "1"}, {"-1", "9223372036854775807"}}},
+			want: "85070591730234615847396907784232501250",
+		},
+		{
+			name: "determinant unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{matrix: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+		{
+			name: "determinant happy path with float type",
+			when: "the matrix consists floating-point numbers",
+			then: "the float determinant should be returned",
+
+			args: args{typ: Float, matrix: [][]string{{"0", "2.5"}, {"2", "1"}}},
+			want: "-5",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.matrix).Determinant(typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Mul(t *testing.T) {
+	type args struct {
+		typ Type
+		a   [][]string
+		b   [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "multiply matrices happy path",
+			when: "the columns of a match the rows of b",
+			then: "the matrix product should be returned",
+
+			args: args{
+				a: [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+				b: [][]string{{"7", "8"}, {"9", "10"}, {"11", "12"}},
+			},
+			want: [][]string{{"58", "64"}, {"139", "154"}},
+		},
+		{
+			name: "multiply matrices happy path with square matrices",
+			when: "b is the identity matrix",
+			then: "a should be returned",
+
+			args: args{
+				a: validIntMatrix,
+				b: [][]string{{"1", "0", "0"}, {"0", "1", "0"}, {"0", "0", "1"}},
+			},
+			want: validIntMatrix,
+		},
+		{
+			name: "multiply matrices unhappy path with dimensions mismatch",
+			when: "the columns of a don't match the rows of b",
+			then: "error should be returned",
+
+			args: args{
+				a: [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+				b: [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+			},
+			wantErr: ErrDimensionMismatch,
+		},
+		{
+			name: "multiply matrices unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{a: matrixWithStrings, b: validIntMatrix},
+			wantErr: ErrNonInteger,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.a).Mul(mustNew(tt.args.b), typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Add(t *testing.T) {
+	type args struct {
+		typ Type
+		a   [][]string
+		b   [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "add matrices happy path",
+			when: "everything is OK",
+			then: "the element-wise sum should be returned",
+
+			args: args{a: validIntMatrix, b: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}},
+			want: [][]string{{"3", "1", "3"}, {"3", "7", "5"}, {"7", "7", "11"}},
+		},
+		{
+			name: "add matrices unhappy path with dimensions mismatch",
+			when: "the shapes of a and b differ",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			wantErr: ErrDimensionMismatch,
+		},
+		{
+			name: "add matrices unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.a).Add(mustNew(tt.args.b), typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Sub(t *testing.T) {
+	type args struct {
+		typ Type
+		a   [][]string
+		b   [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "subtract matrices happy path",
+			when: "everything is OK",
+			then: "the element-wise difference should be returned",
+
+			args: args{a: validIntMatrix, b: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}},
+			want: [][]string{{"-1", "3", "3"}, {"5", "3", "7"}, {"7", "9", "7"}},
+		},
+		{
+			name: "subtract matrices unhappy path with dimensions mismatch",
+			when: "the shapes of a and b differ",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			wantErr: ErrDimensionMismatch,
+		},
+		{
+			name: "subtract matrices unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.a).Sub(mustNew(tt.args.b), typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Hadamard(t *testing.T) {
+	type args struct {
+		typ Type
+		a   [][]string
+		b   [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "hadamard matrices happy path",
+			when: "everything is OK",
+			then: "the element-wise product should be returned",
+
+			args: args{a: validIntMatrix, b: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}},
+			want: [][]string{{"2", "-2", "0"}, {"-4", "10", "-6"}, {"0", "-8", "18"}},
+		},
+		{
+			name: "hadamard matrices unhappy path with dimensions mismatch",
+			when: "the shapes of a and b differ",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			wantErr: ErrDimensionMismatch,
+		},
+		{
+			name: "hadamard matrices unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.a).Hadamard(mustNew(tt.args.b), typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Div(t *testing.T) {
+	type args struct {
+		typ Type
+		a   [][]string
+		b   [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "divide matrices happy path",
+			when: "everything is OK",
+			then: "the element-wise quotient with exact fractions should be returned",
+
+			args: args{a: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}, b: validIntMatrix},
+			want: [][]string{{"2", "-1/2", "0"}, {"-1/4", "2/5", "-1/6"}, {"0", "-1/8", "2/9"}},
+		},
+		{
+			name: "divide matrices unhappy path with division by zero",
+			when: "the divisor matrix has zero element",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: [][]string{{"2", "-1", "0"}, {"-1", "2", "-1"}, {"0", "-1", "2"}}},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name: "divide matrices unhappy path with dimensions mismatch",
+			when: "the shapes of a and b differ",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			wantErr: ErrDimensionMismatch,
+		},
+		{
+			name: "divide matrices unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{a: validIntMatrix, b: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+		{
+			name: "divide matrices happy path with decimal type",
+			when: "the matrices consist decimals",
+			then: "the element-wise quotient with decimals should be returned",
+
+			args: args{typ: Decimal, a: [][]string{{"1", "2.5"}}, b: [][]string{{"8", "-0.5"}}},
+			want: [][]string{{"0.125", "-5"}},
+		},
+		{
+			name: "divide matrices unhappy path with float type",
+			when: "the divisor matrix has zero element",
+			then: "error should be returned instead of infinity",
+
+			args:    args{typ: Float, a: [][]string{{"1.5"}}, b: [][]string{{"0.0"}}},
+			wantErr: ErrDivisionByZero,
+		},
+		{
+			name: "divide matrices happy path with bigint type",
+			when: "the quotients aren't integers",
+			then: "the quotients truncated toward zero should be returned",
+
+			args: args{typ: BigInt, a: [][]string{{"7", "-7"}}, b: [][]string{{"2", "2"}}},
+			want: [][]string{{"3", "-3"}},
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.a).Div(mustNew(tt.args.b), typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_Trace(t *testing.T) {
+	type args struct {
+		typ    Type
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "trace happy path",
+			when: "everything is OK",
+			then: "the sum of the main diagonal elements should be returned",
+
+			args: args{matrix: validIntMatrix},
+			want: "15",
+		},
+		{
+			name: "trace unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{matrix: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mustNew(tt.args.matrix).Trace(typeOrInt(tt.args.typ))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseMatrix(t *testing.T) {
+	type args struct {
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "string matrix to int happy path",
+			when: "everything is OK",
+			then: "the int matrix should be returned",
+
+			args: args{matrix: validIntMatrix},
+			want: validIntMatrix,
+		},
+		{
+			name: "string matrix to int happy path with big numbers",
+			when: "the matrix consists numbers which don't fit into int64",
+			then: "the int matrix should be returned without precision loss",
+
+			args: args{matrix: [][]string{{"123456789012345678901234567890"}}},
+			want: [][]string{{"123456789012345678901234567890"}},
+		},
+		{
+			name: "string matrix to int unhappy path",
+			when: "the initial matrix consists strings",
+			then: "the error should be returned",
+
+			args:    args{matrix: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+		{
+			name: "string matrix to int unhappy path with decimals",
+			when: "the initial matrix consists decimal numbers",
+			then: "the error should be returned",
+
+			args:    args{matrix: [][]string{{"1.5"}}},
+			wantErr: ErrNonInteger,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMatrix[*big.Rat](intArithmetic{}, mustNew(tt.args.matrix))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if formatted := got.Text().Records(); !reflect.DeepEqual(formatted, tt.want) {
+				t.Errorf(errTemplate, meta, formatted, tt.want)
+			}
+		})
+	}
+}
+
+func TestSumRows(t *testing.T) {
+	type args struct {
+		typ    Type
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    string
+		wantErr error
+	}{
+		{
+			name: "matrix to string happy path",
+			when: "everything is OK",
+			then: "the sum os matrix elements should be returned",
+
+			args: args{matrix: validIntMatrix},
+			want: "45",
+		},
+		{
+			name: "matrix sum happy path with rectangular matrix",
+			when: "the matrix is not square",
+			then: "the sum of matrix elements should be returned",
+
+			args: args{matrix: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			want: "21",
+		},
+		{
+			name: "matrix sum happy path with overflow",
+			when: "the sum doesn't fit into int64",
+			then: "the exact sum of matrix elements should be returned",
+
+			args: args{matrix: overflowIntMatrix},
+			want: "9223372036854775816",
+		},
+		{
+			name: "matrix to string unhappy path",
+			when: "matrix consists the non-interger elements",
+			then: "error should be returned",
+
+			args:    args{matrix: matrixWithStrings},
+			wantErr: ErrNonInteger,
+		},
+		{
+			name: "matrix sum happy path with float type",
+			when: "the matrix consists floating-point numbers",
+			then: "the float sum of matrix elements should be returned",
+
+			args: args{typ: Float, matrix: [][]string{{"1.5", "2e3"}, {"-0.25", "1"}}},
+			want: "2002.25",
+		},
+		{
+			name: "matrix sum happy path with decimal type",
+			when: "the matrix consists decimals which aren't exact in binary floating-point",
+			then: "the exact decimal sum should be returned",
+
+			args: args{typ: Decimal, matrix: [][]string{{"0.1", "0.2"}, {"0.3", "0.4"}}},
+			want: "1",
+		},
+		{
+			name: "matrix sum unhappy path with float type",
+			when: "matrix consists the non-float elements",
+			then: "error should be returned",
+
+			args:    args{typ: Float, matrix: matrixWithStrings},
+			wantErr: ErrNonFloat,
+		},
+		{
+			name: "matrix sum unhappy path with decimal type",
+			when: "matrix consists fractions",
+			then: "error should be returned",
+
+			args:    args{typ: Decimal, matrix: [][]string{{"1/3"}}},
+			wantErr: ErrNonDecimal,
+		},
+		{
+			name: "matrix sum happy path with rational type",
+			when: "the matrix consists fractions and decimals",
+			then: "the exact fraction should be returned",
+
+			args: args{typ: Rational, matrix: [][]string{{"1/3", "0.5"}}},
+			want: "5/6",
+		},
+		{
+			name: "matrix sum happy path with complex type",
+			when: "the matrix consists complex numbers",
+			then: "the complex sum should be returned",
+
+			args: args{typ: Complex, matrix: [][]string{{"1+2i", "3"}, {"-1i", "0.5"}}},
+			want: "4.5+1i",
+		},
+		{
+			name: "matrix sum happy path with bigint type",
+			when: "the sum doesn't fit into int64",
+			then: "the exact sum should be returned",
+
+			args: args{typ: BigInt, matrix: overflowIntMatrix},
+			want: "9223372036854775816",
+		},
+		{
+			name: "matrix sum unhappy path with int64 type",
+			when: "the element doesn't fit into int64",
+			then: "error should be returned",
+
+			args:    args{typ: Int64, matrix: [][]string{{"9223372036854775808"}}},
+			wantErr: ErrNonInt64,
+		},
+		{
+			name: "matrix sum unhappy path with int64 overflow",
+			when: "the sum of int64 elements doesn't fit into int64",
+			then: "error should be returned instead of the wrapped sum",
+
+			args:    args{typ: Int64, matrix: [][]string{{"9223372036854775807", "1"}}},
+			wantErr: ErrOverflow,
+		},
+		{
+			name: "matrix sum happy path with int64 type",
+			when: "the partial sums fit into int64",
+			then: "the int64 sum should be returned",
+
+			args: args{typ: Int64, matrix: [][]string{{"-9223372036854775808", "9223372036854775807", "1"}}},
+			want: "0",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SumRows(typeOrInt(tt.args.typ), mustNew(tt.args.matrix).RowReader())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestText_IsSquare(t *testing.T) {
+	type args struct {
+		matrix [][]string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want bool
+	}{
+		{
+			name: "is matrix square happy path",
+			when: "the matrix is in square format",
+			then: "true should be returned",
+
+			args: args{matrix: validIntMatrix},
+			want: true,
+		},
+		{
+			name: "is matrix square happy path",
+			when: "the matrix is not in square format",
+			then: "false should be returned",
+
+			args: args{matrix: [][]string{{"1", "2", "3"}, {"4", "5", "6"}}},
+			want: false,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mustNew(tt.args.matrix).IsSquare(); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+}
+
+// typeOrInt gets the numeric type, int is used when the type isn't specified
+func typeOrInt(typ Type) Type {
+	if typ == "" {
+		return Int
+	}
+	return typ
+}
+
+// mustNew makes the matrix of the valid test records
+func mustNew(records [][]string) *Text {
+	m, err := NewText(records)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package matrix
+
+// SumRows gets the sum of the elements parsed as t, the rows are read one by one without holding the whole matrix
+func SumRows(t Type, rows RowReader) (string, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return "", err
+	}
+	return ops.sum(rows)
+}
+
+// ProductRows gets the product of the elements parsed as t, the rows are read one by one without holding the whole matrix
+func ProductRows(t Type, rows RowReader) (string, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return "", err
+	}
+	return ops.multiply(rows)
+}
+
+// Sum gets the sum of the elements parsed as t
+func (m *Text) Sum(t Type) (string, error) {
+	return SumRows(t, m.RowReader())
+}
+
+// Product gets the product of the elements parsed as t
+func (m *Text) Product(t Type) (string, error) {
+	return ProductRows(t, m.RowReader())
+}
+
+// Trace gets the sum of the main diagonal elements of the square matrix
+func (m *Text) Trace(t Type) (string, error) {
+	ops, err := m.squareOperations(t)
+	if err != nil {
+		return "", err
+	}
+	return ops.trace(m)
+}
+
+// Determinant gets the determinant of the square matrix, it's exact for the int and decimal types
+func (m *Text) Determinant(t Type) (string, error) {
+	ops, err := m.squareOperations(t)
+	if err != nil {
+		return "", err
+	}
+	return ops.determinant(m)
+}
+
+// Inverse gets the inverse of the square matrix, ErrSingular is returned when it doesn't exist
+func (m *Text) Inverse(t Type) (*Text, error) {
+	ops, err := m.squareOperations(t)
+	if err != nil {
+		return nil, err
+	}
+	return ops.inverse(m)
+}
+
+// Mul gets the matrix product m·b, the number of columns of m should match the number of rows of b
+func (m *Text) Mul(b *Text, t Type) (*Text, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.matmul(m, b)
+}
+
+// Add gets the element-wise sum m+b of matrices of the same shape
+func (m *Text) Add(b *Text, t Type) (*Text, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.add(m, b)
+}
+
+// Sub gets the element-wise difference m-b of matrices of the same shape
+func (m *Text) Sub(b *Text, t Type) (*Text, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.subtract(m, b)
+}
+
+// Hadamard gets the element-wise product m∘b of matrices of the same shape
+func (m *Text) Hadamard(b *Text, t Type) (*Text, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.hadamard(m, b)
+}
+
+// Div gets the element-wise quotient m/b of matrices of the same shape, all zero divisors are reported at once
+func (m *Text) Div(b *Text, t Type) (*Text, error) {
+	ops, err := t.operations()
+	if err != nil {
+		return nil, err
+	}
+	return ops.divide(m, b)
+}
+
+// squareOperations gets the operations of t for the operation defined for square matrices only
+func (m *Text) squareOperations(t Type) (operations, error) {
+	if !m.IsSquare() {
+		return operations{}, ErrNotSquare
+	}
+	return t.operations()
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"matrix/pkg/matrix"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	// problemTypePrefix makes the problem type URI from the error code, e.g. urn:matrix:problem:singular_matrix
+	problemTypePrefix = "urn:matrix:problem:"
+)
+
+// problemTypes maps the errors to the stable codes and status codes reported to the clients.
+// The codes are the part of API and must not be changed, the first matching error is used
+var problemTypes = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{err: errBodyTooLarge, code: "body_too_large", status: http.StatusRequestEntityTooLarge},
+	{err: errMatrixTooLarge, code: "matrix_too_large", status: http.StatusUnprocessableEntity},
+	{err: errCellTooLong, code: "cell_too_long", status: http.StatusUnprocessableEntity},
+	{err: matrix.ErrSingular, code: "singular_matrix", status: http.StatusUnprocessableEntity},
+	{err: matrix.ErrDivisionByZero, code: "division_by_zero", status: http.StatusUnprocessableEntity},
+	{err: matrix.ErrOverflow, code: "int64_overflow", status: http.StatusUnprocessableEntity},
+
+	{err: errNonNumericMatrixMarket, code: "non_numeric_matrix", status: http.StatusNotAcceptable},
+	{err: errNonRealNpy, code: "non_real_matrix", status: http.StatusNotAcceptable},
+	{err: errBigIntNpy, code: "big_integer_matrix", status: http.StatusNotAcceptable},
+
+	{err: errInvalidFileFormat, code: "invalid_file_format", status: http.StatusBadRequest},
+	{err: errInvalidMatrixMarket, code: "invalid_matrix_market", status: http.StatusBadRequest},
+	{err: errInvalidNpy, code: "invalid_npy", status: http.StatusBadRequest},
+	{err: errInvalidXlsx, code: "invalid_xlsx", status: http.StatusBadRequest},
+	{err: errUnknownSheet, code: "unknown_sheet", status: http.StatusBadRequest},
+	{err: errInvalidRange, code: "invalid_range", status: http.StatusBadRequest},
+	{err: errMergedCells, code: "merged_cells", status: http.StatusBadRequest},
+	{err: errEmptyCells, code: "empty_cells", status: http.StatusBadRequest},
+	{err: errUncachedFormula, code: "uncached_formula", status: http.StatusBadRequest},
+	{err: errUnknownFormat, code: "unknown_format", status: http.StatusBadRequest},
+	{err: errInvalidIndices, code: "invalid_indices", status: http.StatusBadRequest},
+	{err: errInvalidLabels, code: "invalid_labels", status: http.StatusBadRequest},
+	{err: matrix.ErrUnknownColorMap, code: "unknown_color_map", status: http.StatusBadRequest},
+	{err: errInvalidTriplets, code: "invalid_coo", status: http.StatusBadRequest},
+	{err: errUnknownStorage, code: "unknown_storage", status: http.StatusBadRequest},
+	{err: matrix.ErrOutOfRange, code: "entry_out_of_range", status: http.StatusBadRequest},
+	{err: matrix.ErrDuplicateEntry, code: "duplicate_entry", status: http.StatusBadRequest},
+	{err: errMissingFile, code: "missing_file", status: http.StatusBadRequest},
+	{err: errSingleCsvBody, code: "single_csv_body", status: http.StatusBadRequest},
+	{err: errInvalidJSONBody, code: "invalid_json_body", status: http.StatusBadRequest},
+	{err: errInvalidJSONElement, code: "invalid_json_element", status: http.StatusBadRequest},
+	{err: errMissingJSONOperand, code: "missing_operand", status: http.StatusBadRequest},
+	{err: csv.ErrFieldCount, code: "ragged_matrix", status: http.StatusBadRequest},
+	{err: matrix.ErrRagged, code: "ragged_matrix", status: http.StatusBadRequest},
+	{err: matrix.ErrEmpty, code: "empty_matrix", status: http.StatusBadRequest},
+	{err: matrix.ErrNotSquare, code: "not_square_matrix", status: http.StatusBadRequest},
+	{err: matrix.ErrDimensionMismatch, code: "dimension_mismatch", status: http.StatusBadRequest},
+	{err: matrix.ErrUnknownType, code: "unknown_numeric_type", status: http.StatusBadRequest},
+	{err: matrix.ErrUnsupported, code: "unsupported_operation", status: http.StatusBadRequest},
+	{err: matrix.ErrNonInteger, code: "non_integer_element", status: http.StatusBadRequest},
+	{err: matrix.ErrNonInt64, code: "non_int64_element", status: http.StatusBadRequest},
+	{err: matrix.ErrNonRational, code: "non_rational_element", status: http.StatusBadRequest},
+	{err: matrix.ErrNonDecimal, code: "non_decimal_element", status: http.StatusBadRequest},
+	{err: matrix.ErrNonFloat, code: "non_float_element", status: http.StatusBadRequest},
+	{err: matrix.ErrNonComplex, code: "non_complex_element", status: http.StatusBadRequest},
+}
+
+const (
+	// the codes of the errors which aren't listed in problemTypes
+	invalidCsvCode = "invalid_csv"
+	badRequestCode = "bad_request"
+)
+
+// problem is the RFC 7807 representation of the error
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail"`
+	// Code is the stable identifier of the error, the same as the last part of Type
+	Code string `json:"code"`
+
+	// Cells lists the invalid matrix elements
+	Cells []problemCell `json:"cells,omitempty"`
+	// CSV is the position of the CSV syntax error
+	CSV *problemCSVPosition `json:"csv,omitempty"`
+}
+
+// problemCell is the location of the invalid matrix element, the row and column are 1-based
+type problemCell struct {
+	Row    int    `json:"row"`
+	Column int    `json:"column"`
+	Matrix string `json:"matrix,omitempty"` // operand of the binary operation, "a" or "b"
+	Value  string `json:"value,omitempty"`
+}
+
+// problemCSVPosition is the position in the CSV input, the line and column are 1-based
+type problemCSVPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// newProblem builds the RFC 7807 representation of the error
+func newProblem(err error) problem {
+	p := problem{Code: badRequestCode, Title: "bad request", Status: http.StatusBadRequest, Detail: err.Error()}
+	for _, typ := range problemTypes {
+		if errors.Is(err, typ.err) {
+			p.Code, p.Title, p.Status = typ.code, typ.err.Error(), typ.status
+			break
+		}
+	}
+
+	var parseErr *csv.ParseError
+	var cellErrs *matrix.CellErrors
+	var cellErr *matrix.CellError
+	switch {
+	case errors.As(err, &parseErr):
+		if p.Code == badRequestCode {
+			p.Code, p.Title = invalidCsvCode, "invalid CSV"
+		}
+		p.CSV = &problemCSVPosition{Line: parseErr.Line, Column: parseErr.Column}
+	case errors.As(err, &cellErrs):
+		for _, cell := range cellErrs.Cells {
+			p.Cells = append(p.Cells, newProblemCell(cell))
+		}
+	case errors.As(err, &cellErr):
+		p.Cells = []problemCell{newProblemCell(cellErr)}
+	}
+
+	p.Type = problemTypePrefix + p.Code
+	return p
+}
+
+func newProblemCell(cell *matrix.CellError) problemCell {
+	return problemCell{Row: cell.Row, Column: cell.Col, Matrix: cell.Operand, Value: cell.Value}
+}
+
+// writeError writes the error as RFC 7807 problem when the client accepts JSON, and as plain text otherwise
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err = fmt.Errorf("%w: more than %d bytes, the max upload size limit", errBodyTooLarge, maxBytesErr.Limit)
+	}
+
+	p := newProblem(err)
+	if !acceptsProblem(r) {
+		http.Error(w, p.Detail, p.Status)
+		return
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		// the status is already sent, so it's only possible to log the error
+		logError(err)
+	}
+}
+
+// acceptsProblem checks if the client asks for the JSON response, either the problem or the regular one
+func acceptsProblem(r *http.Request) bool {
+	return responseFormat(r) == jsonFormat || hasMediaType(r.Header.Get("Accept"), problemContentType)
+}
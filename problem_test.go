@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_newProblem(t *testing.T) {
+	var manyCells matrix.CellErrors
+	for i := 0; i < matrix.MaxCellErrors+5; i++ {
+		manyCells.Add(&matrix.CellError{Err: matrix.ErrNonInteger, Row: i + 1, Col: 1, Value: "x"})
+	}
+
+	type args struct {
+		err error
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want problem
+	}{
+		{
+			name: "new problem happy path",
+			when: "the error is one of the known errors",
+			then: "the problem with its code and status should be returned",
+
+			args: args{err: matrix.ErrNotSquare},
+			want: problem{
+				Type:   "urn:matrix:problem:not_square_matrix",
+				Title:  "matrix should be square",
+				Status: http.StatusBadRequest,
+				Detail: "matrix should be square",
+				Code:   "not_square_matrix",
+			},
+		},
+		{
+			name: "new problem happy path with wrapped error",
+			when: "the known error is wrapped with the details",
+			then: "the problem with the code of the wrapped error and the full message as detail should be returned",
+
+			args: args{err: fmt.Errorf("%w: more than 4 rows, the max rows limit", errMatrixTooLarge)},
+			want: problem{
+				Type:   "urn:matrix:problem:matrix_too_large",
+				Title:  "matrix is too large",
+				Status: http.StatusUnprocessableEntity,
+				Detail: "matrix is too large: more than 4 rows, the max rows limit",
+				Code:   "matrix_too_large",
+			},
+		},
+		{
+			name: "new problem happy path with cells",
+			when: "the error lists several invalid elements",
+			then: "the problem with all cells should be returned",
+
+			args: args{err: func() error {
+				var errs matrix.CellErrors
+				errs.Add(&matrix.CellError{Err: matrix.ErrNonInteger, Row: 1, Col: 2, Operand: "a", Value: "x"})
+				errs.Add(&matrix.CellError{Err: matrix.ErrNonInteger, Row: 3, Col: 1, Operand: "b", Value: "1.5"})
+				return errs.Err()
+			}()},
+			want: problem{
+				Type:   "urn:matrix:problem:non_integer_element",
+				Title:  "only integers allowed in matrix",
+				Status: http.StatusBadRequest,
+				Detail: `only integers allowed in matrix: "x" at row 1, column 2 of matrix a; "1.5" at row 3, column 1 of matrix b`,
+				Code:   "non_integer_element",
+				Cells: []problemCell{
+					{Row: 1, Column: 2, Matrix: "a", Value: "x"},
+					{Row: 3, Column: 1, Matrix: "b", Value: "1.5"},
+				},
+			},
+		},
+		{
+			name: "new problem happy path with CSV parse error",
+			when: "the CSV has the syntax error",
+			then: "the problem with the CSV line and column should be returned",
+
+			args: args{err: &csv.ParseError{StartLine: 2, Line: 2, Column: 3, Err: csv.ErrBareQuote}},
+			want: problem{
+				Type:   "urn:matrix:problem:invalid_csv",
+				Title:  "invalid CSV",
+				Status: http.StatusBadRequest,
+				Detail: `parse error on line 2, column 3: bare " in non-quoted-field`,
+				Code:   "invalid_csv",
+				CSV:    &problemCSVPosition{Line: 2, Column: 3},
+			},
+		},
+		{
+			name: "new problem happy path with unknown error",
+			when: "the error isn't one of the known errors",
+			then: "the generic bad request problem should be returned",
+
+			args: args{err: errors.New("something went wrong")},
+			want: problem{
+				Type:   "urn:matrix:problem:bad_request",
+				Title:  "bad request",
+				Status: http.StatusBadRequest,
+				Detail: "something went wrong",
+				Code:   "bad_request",
+			},
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			got := newProblem(tt.args.err)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+		})
+	}
+
+	// only the first matrix.MaxCellErrors cells are listed, and the rest are counted in detail
+	got := newProblem(manyCells.Err())
+	if len(got.Cells) != matrix.MaxCellErrors {
+		t.Errorf(errTemplate, "the number of listed cells", len(got.Cells), matrix.MaxCellErrors)
+	}
+	if !strings.HasSuffix(got.Detail, " and 5 more") {
+		t.Errorf(errTemplate, "the number of dropped cells", got.Detail, "... and 5 more")
+	}
+}
+
+func TestHandler_Problem(t *testing.T) {
+	handler := NewHandler(defaultConfig().limits)
+
+	nonIntegerReq := SetupJSONRequest(`{"data":[[1,"x"],["1.5",4]]}`, "/sum", t)
+	nonIntegerReq.Header.Set("Accept", "application/problem+json")
+
+	raggedCsvReq := SetupJSONRequest("1,2\n3\n", "/transpose", t)
+	raggedCsvReq.Header.Set("Content-Type", "text/csv")
+	raggedCsvReq.Header.Set("Accept", "application/json")
+
+	plainReq := SetupJSONRequest(`{"data":[[1,"x"]]}`, "/sum", t)
+
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args            args
+		wantCode        int
+		wantContentType string
+		wantBody        string
+	}{
+		{
+			name: "problem happy path with cells",
+			when: "the client accepts problem JSON and the matrix has invalid elements",
+			then: "the problem with all invalid cells should be returned",
+
+			args:            args{req: nonIntegerReq},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: problemContentType,
+			wantBody: `{"type":"urn:matrix:problem:non_integer_element","title":"only integers allowed in matrix",` +
+				`"status":400,"detail":"only integers allowed in matrix: \"x\" at row 1, column 2; \"1.5\" at row 2, column 1",` +
+				`"code":"non_integer_element","cells":[{"row":1,"column":2,"value":"x"},{"row":2,"column":1,"value":"1.5"}]}` + "\n",
+		},
+		{
+			name: "problem happy path with CSV position",
+			when: "the client accepts JSON and the CSV rows have different length",
+			then: "the problem with the CSV line and column should be returned",
+
+			args:            args{req: raggedCsvReq},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: problemContentType,
+			wantBody: `{"type":"urn:matrix:problem:ragged_matrix","title":"wrong number of fields",` +
+				`"status":400,"detail":"record on line 2: wrong number of fields",` +
+				`"code":"ragged_matrix","csv":{"line":2,"column":1}}` + "\n",
+		},
+		{
+			name: "problem happy path with plain text",
+			when: "the client doesn't accept JSON",
+			then: "the detail of the problem should be returned as plain text",
+
+			args:            args{req: plainReq},
+			wantCode:        http.StatusBadRequest,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody:        "only integers allowed in matrix: \"x\" at row 1, column 2\n",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, tt.args.req)
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf(errTemplate, meta, recorder.Code, tt.wantCode)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf(errTemplate, meta, got, tt.wantContentType)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf(errTemplate, meta, got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"matrix/pkg/matrix"
+)
+
+// Operation is the matrix operation served as the route "/" + Name and run as the command of the same name.
+// The operations are listed in operations, which is the only place to add them: the routes of NewHandler,
+// the commands, the /operations listing and the browser UI are generated from it
+type Operation interface {
+	Name() string
+	// Usage is the one-line description of the result, e.g. "the sum of the elements"
+	Usage() string
+	// Arity is 1 for the operations on one matrix, and 2 for the binary ones on the "a" and "b" operands
+	Arity() int
+	// Shape is checked for each operand read whole, the shapes of the binary operands are checked by Compute
+	Shape() shapeRequirement
+	// Numeric operations parse the elements as the type requested with the "type" query parameter or the -type flag,
+	// the rest keep the elements as text
+	Numeric() bool
+	// Input is how the operands are read before Compute
+	Input() input
+	Compute(args operationArgs) (result, error)
+}
+
+// shapeRequirement describes the shape of matrix the operation is defined for
+type shapeRequirement int
+
+const (
+	anyShape    shapeRequirement = iota // any n×m matrix
+	squareShape                         // n×n matrix only
+)
+
+func (s shapeRequirement) String() string {
+	if s == squareShape {
+		return "square"
+	}
+	return "any"
+}
+
+// input is how the operands are read for the operation
+type input int
+
+const (
+	// rowsInput streams the rows, so the matrices which don't fit into memory can be processed,
+	// the sparse files are kept sparse, see sparseRows
+	rowsInput input = iota
+	// storedInput reads the whole matrix in the storage requested with the "storage" query parameter, see storeRows
+	storedInput
+	// denseInput reads the whole matrix as the dense one
+	denseInput
+)
+
+// operand is the matrix read for the operation, rows is set for rowsInput and stored is set for the other inputs
+type operand struct {
+	rows matrix.RowReader
+	stored
+}
+
+// operationArgs are the operands read as the operation requires, and the parameters of the request or the command
+type operationArgs struct {
+	operands []operand
+	typ      matrix.Type // empty for the non-numeric operations
+	storage  storage     // empty for the operations with denseInput
+}
+
+// result is the result of the operation: the matrix, the list or the scalar. Only one of rows, dense and sparse is set
+// for the matrix and the list, the scalar has none of them
+type result struct {
+	rows   matrix.RowReader // the matrix streamed row by row
+	list   bool             // the rows are written in one line
+	dense  *matrix.Text
+	sparse *matrix.SparseText
+	scalar string
+}
+
+// denseResult, sparseResult and scalarResult wrap the results of the matrix package,
+// so Compute can return them in one line
+func denseResult(m *matrix.Text, err error) (result, error) {
+	return result{dense: m}, err
+}
+
+func sparseResult(s *matrix.SparseText, err error) (result, error) {
+	return result{sparse: s}, err
+}
+
+func scalarResult(value string, err error) (result, error) {
+	return result{scalar: value}, err
+}
+
+// operation is the Operation described by its fields, the registered operations are built with it
+type operation struct {
+	name    string
+	usage   string
+	arity   int
+	shape   shapeRequirement
+	numeric bool
+	input   input
+	compute func(args operationArgs) (result, error)
+}
+
+func (o operation) Name() string                               { return o.name }
+func (o operation) Usage() string                              { return o.usage }
+func (o operation) Arity() int                                 { return o.arity }
+func (o operation) Shape() shapeRequirement                    { return o.shape }
+func (o operation) Numeric() bool                              { return o.numeric }
+func (o operation) Input() input                               { return o.input }
+func (o operation) Compute(args operationArgs) (result, error) { return o.compute(args) }
+
+// lookupOperation finds the registered operation by name
+func lookupOperation(name string) (Operation, bool) {
+	for _, op := range operations {
+		if op.Name() == name {
+			return op, true
+		}
+	}
+	return nil, false
+}
+
+// readOperand reads the operand as the operation requires, the shape is checked when the matrix is read whole
+func readOperand(rows matrix.RowReader, op Operation, st storage, l limits) (operand, error) {
+	var m stored
+	switch op.Input() {
+	case rowsInput:
+		return operand{rows: rows}, nil
+	case storedInput:
+		var err error
+		if m, err = storeRows(rows, st, l); err != nil {
+			return operand{}, err
+		}
+	default:
+		dense, err := matrix.ReadAll(rows)
+		if err != nil {
+			return operand{}, err
+		}
+		m.dense = dense
+	}
+	if op.Shape() == squareShape && !m.isSquare() {
+		return operand{}, matrix.ErrNotSquare
+	}
+	return operand{stored: m}, nil
+}
+
+// serveOperation reads the operands of the request, computes the operation and writes the result in the response format
+func serveOperation(op Operation) http.Handler {
+	var handler http.Handler = operandsMiddleware(op, func(w http.ResponseWriter, r *http.Request) {
+		args := operationArgs{operands: getOperandsFromCtx(r.Context())}
+		args.typ, _ = r.Context().Value(numericTypeKey).(matrix.Type)
+		args.storage, _ = r.Context().Value(storageKey).(storage)
+		res, err := op.Compute(args)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeResult(w, r, res)
+	})
+	if op.Input() != denseInput {
+		handler = storageMiddleware(handler.ServeHTTP)
+	}
+	if op.Numeric() {
+		handler = numericMiddleware(handler.ServeHTTP)
+	}
+	return handler
+}
+
+// operandsMiddleware reads the operands of the operation from the request, the file of the multipart form
+// or the "a" and "b" files of the binary operation
+func operandsMiddleware(op Operation, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var opened []matrix.RowReader
+		if op.Arity() == 2 {
+			a, b, err := openOperands(w, r)
+			if err != nil {
+				// writeError call inside openOperands
+				logDebug(err)
+				return
+			}
+			opened = []matrix.RowReader{a, b}
+		} else {
+			rows, err := openRows(w, r)
+			if err != nil {
+				// writeError call inside openRows
+				logDebug(err)
+				return
+			}
+			opened = []matrix.RowReader{rows}
+		}
+
+		st, _ := r.Context().Value(storageKey).(storage)
+		ops := make([]operand, len(opened))
+		for i, rows := range opened {
+			var err error
+			if ops[i], err = readOperand(rows, op, st, getLimitsFromCtx(r.Context())); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+		ctxWithOperands := context.WithValue(r.Context(), operandsKey, ops)
+		handler.ServeHTTP(w, r.WithContext(ctxWithOperands))
+	}
+}
+
+func getOperandsFromCtx(ctx context.Context) []operand {
+	return ctx.Value(operandsKey).([]operand)
+}
+
+// writeResult writes the result in the response format. The CSV rows are streamed as soon as they're read,
+// while the JSON, Matrix Market, COO and NumPy responses hold the shape before the data, so they can't be streamed
+func writeResult(w http.ResponseWriter, r *http.Request, res result) {
+	switch {
+	case res.rows != nil && responseFormat(r) != csvFormat:
+		m, err := matrix.ReadAll(res.rows)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if res.list {
+			writeList(w, r, m.Flatten())
+			return
+		}
+		writeMatrix(w, r, m)
+	case res.rows != nil && res.list:
+		streamRows(w, r, res.rows, func(out io.Writer, i int, row []string) {
+			if i > 0 {
+				fmt.Fprint(out, ",")
+			}
+			fmt.Fprint(out, strings.Join(row, ","))
+		})
+	case res.rows != nil:
+		streamRows(w, r, res.rows, func(out io.Writer, _ int, row []string) {
+			fmt.Fprintf(out, "%s\n", strings.Join(row, ","))
+		})
+	case res.sparse != nil:
+		writeSparse(w, r, res.sparse)
+	case res.dense != nil:
+		writeMatrix(w, r, res.dense)
+	default:
+		writeScalar(w, r, res.scalar)
+	}
+}
+
+// jsonOperation is the JSON representation of the operation in the /operations listing
+type jsonOperation struct {
+	Name     string   `json:"name"`
+	Usage    string   `json:"usage"`
+	Route    string   `json:"route"`
+	Operands []string `json:"operands"` // the keys of the multipart form files
+	Shape    string   `json:"shape"`
+	Numeric  bool     `json:"numeric"` // the "type" query parameter is accepted
+	Storage  bool     `json:"storage"` // the "storage" query parameter is accepted
+}
+
+func newJSONOperation(op Operation) jsonOperation {
+	operands := []string{multipartFileKey}
+	if op.Arity() == 2 {
+		operands = []string{multipartLeftOperandKey, multipartRightOperandKey}
+	}
+	return jsonOperation{
+		Name:     op.Name(),
+		Usage:    op.Usage(),
+		Route:    "/" + op.Name(),
+		Operands: operands,
+		Shape:    op.Shape().String(),
+		Numeric:  op.Numeric(),
+		Storage:  op.Input() != denseInput,
+	}
+}
+
+// Operations lists the registered operations, so the clients can discover them
+func (Handler) Operations(w http.ResponseWriter, r *http.Request) {
+	list := make([]jsonOperation, len(operations))
+	for i, op := range operations {
+		list[i] = newJSONOperation(op)
+	}
+	writeJSON(w, struct {
+		Operations []jsonOperation `json:"operations"`
+	}{Operations: list})
+}
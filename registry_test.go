@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test_operations runs every registered operation as the route and as the command,
+// so the new operation is tested by its registration alone
+func Test_operations(t *testing.T) {
+	handler := NewHandler(defaultConfig().limits)
+	names := map[string]bool{}
+	for i, op := range operations {
+		meta := fmt.Sprintf(metaTemplate, op.Name(), i, "the operation is registered",
+			"its route and command should return the same result")
+
+		t.Run(op.Name(), func(t *testing.T) {
+			if names[op.Name()] {
+				t.Fatalf(errTemplate, meta, "duplicate name", op.Name())
+			}
+			names[op.Name()] = true
+
+			var req *http.Request
+			var writer *multipart.Writer
+			args := []string{invertiblePath}
+			switch op.Arity() {
+			case 1:
+				req, writer = SetupRequest(invertiblePath, "/"+op.Name(), t)
+			case 2:
+				req, writer = SetupOperandsRequest(invertiblePath, validPath, "/"+op.Name(), t)
+				args = append(args, validPath)
+			default:
+				t.Fatalf(errTemplate, meta, op.Arity(), "1 or 2")
+			}
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf(errTemplate, meta, recorder.Body.String(), http.StatusOK)
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := runCommand(op.Name(), args, strings.NewReader(""), &stdout, &stderr); code != exitOK {
+				t.Fatalf(errTemplate, meta, stderr.String(), exitOK)
+			}
+			// the command ends the scalars and the lists with the newline, the response doesn't
+			if got, want := strings.TrimSuffix(stdout.String(), "\n"), strings.TrimSuffix(recorder.Body.String(), "\n"); got != want {
+				t.Errorf(errTemplate, meta, got, want)
+			}
+		})
+	}
+}
+
+func TestHandler_Operations(t *testing.T) {
+	handler := NewHandler(defaultConfig().limits)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/operations", nil))
+
+	var got struct {
+		Operations []jsonOperation `json:"operations"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Operations) != len(operations) {
+		t.Fatalf(errTemplate, "every operation should be listed", len(got.Operations), len(operations))
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		want jsonOperation
+	}{
+		{
+			name: "operations listing happy path with streamed operation",
+			when: "the operation reads the rows in any storage",
+			then: "the storage should be listed without the type",
+
+			want: jsonOperation{Name: "flatten", Usage: "the matrix elements in one line", Route: "/flatten",
+				Operands: []string{"file"}, Shape: "any", Storage: true},
+		},
+		{
+			name: "operations listing happy path with square matrix",
+			when: "the operation is defined for the square matrices",
+			then: "the square shape and the type should be listed",
+
+			want: jsonOperation{Name: "determinant", Usage: "the determinant of the square matrix", Route: "/determinant",
+				Operands: []string{"file"}, Shape: "square", Numeric: true},
+		},
+		{
+			name: "operations listing happy path with binary operation",
+			when: "the operation has two operands",
+			then: "the keys of both operands should be listed",
+
+			want: jsonOperation{Name: "matmul", Usage: "the matrix product a·b", Route: "/matmul",
+				Operands: []string{"a", "b"}, Shape: "any", Numeric: true, Storage: true},
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			for _, op := range got.Operations {
+				if op.Name == tt.want.Name {
+					if !reflect.DeepEqual(op, tt.want) {
+						t.Errorf(errTemplate, meta, op, tt.want)
+					}
+					return
+				}
+			}
+			t.Errorf(errTemplate, meta, nil, tt.want)
+		})
+	}
+}
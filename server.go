@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const serveCommand = "serve"
+
+// runServer starts the server configured with args and the environment, and returns the exit code after it's stopped
+func runServer(args []string) int {
+	cfg, err := loadConfig(args, os.Getenv)
+	if err != nil {
+		logError(err.Error())
+		return exitUsage
+	}
+	// the level is already checked by loadConfig
+	currentLogLevel, _ = parseLogLevel(cfg.LogLevel)
+	logInfo("effective config:", cfg)
+
+	server := &http.Server{
+		Addr:         net.JoinHostPort(cfg.Host, cfg.Port),
+		Handler:      NewHandler(cfg.limits),
+		ReadTimeout:  cfg.ReadTimeout.Duration,
+		WriteTimeout: cfg.WriteTimeout.Duration,
+		IdleTimeout:  cfg.IdleTimeout.Duration,
+	}
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		logError(fmt.Sprintf("error during http listening: %s", err.Error()))
+		return exitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// the second signal kills the process without waiting for the in-flight requests
+		<-ctx.Done()
+		stop()
+	}()
+
+	logInfo("server is running on " + listener.Addr().String())
+	if err := serve(ctx, server, listener, cfg.ShutdownTimeout.Duration); err != nil {
+		logError(err.Error())
+		return exitFailure
+	}
+	return exitOK
+}
+
+// serve handles the connections from listener until ctx is done, then stops accepting new connections and waits
+// for the in-flight requests to finish within the grace period. The connections still active after it are closed
+func serve(ctx context.Context, server *http.Server, listener net.Listener, grace time.Duration) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("error during http serving: %w", err)
+	case <-ctx.Done():
+	}
+
+	logInfo(fmt.Sprintf("shutting down, waiting up to %s for the in-flight requests", grace))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	// Serve returns http.ErrServerClosed as soon as Shutdown is called
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error during http serving: %w", err)
+	}
+	logInfo("server is stopped")
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_serve(t *testing.T) {
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		handlerDuration time.Duration
+		grace           time.Duration
+		wantStatus      int
+		wantErr         bool
+	}{
+		{
+			name: "serve happy path",
+			when: "the server is stopped during the request, which finishes within the grace period",
+			then: "the request should be completed and serve should return without error",
+
+			handlerDuration: 100 * time.Millisecond,
+			grace:           5 * time.Second,
+			wantStatus:      http.StatusOK,
+		},
+		{
+			name: "serve unhappy path with the grace period exceeded",
+			when: "the server is stopped during the request, which doesn't finish within the grace period",
+			then: "the connection should be closed and serve should return error",
+
+			handlerDuration: 5 * time.Second,
+			grace:           100 * time.Millisecond,
+			wantErr:         true,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			started := make(chan struct{})
+			server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				select {
+				case <-time.After(tt.handlerDuration):
+				case <-r.Context().Done():
+				}
+				w.Write([]byte("done"))
+			})}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- serve(ctx, server, listener, tt.grace)
+			}()
+
+			type response struct {
+				status int
+				err    error
+			}
+			responses := make(chan response, 1)
+			go func() {
+				resp, err := http.Get("http://" + listener.Addr().String())
+				if err != nil {
+					responses <- response{err: err}
+					return
+				}
+				defer resp.Body.Close()
+				_, err = io.ReadAll(resp.Body)
+				responses <- response{status: resp.StatusCode, err: err}
+			}()
+
+			<-started
+			cancel()
+
+			err = <-serveErr
+			if (err != nil) != tt.wantErr {
+				t.Errorf(errTemplate, meta, err, tt.wantErr)
+			}
+			resp := <-responses
+			if tt.wantErr {
+				if resp.err == nil {
+					t.Errorf(errTemplate, meta, resp.status, "closed connection")
+				}
+				return
+			}
+			if resp.err != nil || resp.status != tt.wantStatus {
+				t.Errorf(errTemplate, meta, resp, tt.wantStatus)
+			}
+		})
+	}
+}
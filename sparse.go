@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errUnknownStorage = errors.New("unknown storage, auto, dense and sparse allowed")
+)
+
+// storage is the representation of the matrix requested with the "storage" query parameter
+type storage string
+
+const (
+	// autoStorage keeps the sparse input sparse when at most sparseDensity of its elements are non-zero,
+	// the dense input stays dense, so it's parsed as the requested type like with denseStorage
+	autoStorage   storage = "auto"
+	denseStorage  storage = "dense"
+	sparseStorage storage = "sparse"
+
+	sparseDensity = 0.1
+)
+
+// sparseRows reads the dense rows of the sparse matrix, so it can be passed to any operation. The rows are checked
+// against the limits like the CSV rows, while the operations with the sparse implementation get the matrix itself
+type sparseRows struct {
+	matrix.RowReader
+	sparse *matrix.SparseText
+	limits limits
+	count  int
+}
+
+func newSparseRows(s *matrix.SparseText, l limits) *sparseRows {
+	return &sparseRows{RowReader: s.RowReader(), sparse: s, limits: l}
+}
+
+func (s *sparseRows) Read() ([]string, error) {
+	// the shape is checked before the row is filled with zeros, as the row of the sparse matrix can be huge
+	if err := checkShapeLimits(s.count+1, s.sparse.Cols(), s.limits); err != nil {
+		return nil, err
+	}
+	row, err := s.RowReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	s.count++
+	if err := checkRowLimits(s.count, row, s.limits); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// sparseOf gets the sparse matrix of rows, nil is returned when the rows are dense or the dense storage is requested
+func sparseOf(rows matrix.RowReader, st storage) *matrix.SparseText {
+	if s, ok := rows.(*sparseRows); ok && st != denseStorage {
+		return s.sparse
+	}
+	return nil
+}
+
+// stored is the matrix in the storage chosen with useSparse, only one of the fields is set
+type stored struct {
+	dense  *matrix.Text
+	sparse *matrix.SparseText
+}
+
+// storeRows reads the matrix in the requested storage, the sparse matrix is read as dense one within the dense limits.
+// The dense matrix is converted to the sparse one only when it's requested
+func storeRows(rows matrix.RowReader, st storage, l limits) (stored, error) {
+	if s := sparseOf(rows, st); s != nil && useSparse(st, s, l) {
+		return stored{sparse: s}, nil
+	}
+	m, err := matrix.ReadAll(rows)
+	if err != nil {
+		return stored{}, err
+	}
+	if st == sparseStorage {
+		return stored{sparse: m.Sparse()}, nil
+	}
+	return stored{dense: m}, nil
+}
+
+// useSparse decides if the sparse matrix should be kept sparse. By default it's sparse when at most sparseDensity
+// of its elements are non-zero, or when it's over the limits of the dense matrix
+func useSparse(st storage, s *matrix.SparseText, l limits) bool {
+	switch st {
+	case sparseStorage:
+		return true
+	case denseStorage:
+		return false
+	}
+	return s.Density() <= sparseDensity || checkShapeLimits(s.Rows(), s.Cols(), l) != nil
+}
+
+// sparseText gets the sparse representation of the matrix in any storage
+func (s stored) sparseText() *matrix.SparseText {
+	if s.sparse != nil {
+		return s.sparse
+	}
+	return s.dense.Sparse()
+}
+
+func (s stored) isSquare() bool {
+	if s.sparse != nil {
+		return s.sparse.IsSquare()
+	}
+	return s.dense.IsSquare()
+}
+
+// storageMiddleware resolves the storage requested with the "storage" query parameter, auto is used by default
+func storageMiddleware(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		st := storage(r.URL.Query().Get(storageKey))
+		switch st {
+		case "":
+			st = autoStorage
+		case autoStorage, denseStorage, sparseStorage:
+		default:
+			writeError(w, r, fmt.Errorf("%w: %q", errUnknownStorage, st))
+			return
+		}
+		ctxWithStorage := context.WithValue(r.Context(), storageKey, st)
+		handler.ServeHTTP(w, r.WithContext(ctxWithStorage))
+	}
+}
+
+// writeSparse writes the sparse matrix in the response format. It's written as is in the COO and Matrix Market
+// formats, while the other formats need the dense matrix, which should fit into the limits
+func writeSparse(w http.ResponseWriter, r *http.Request, s *matrix.SparseText) {
+	switch responseFormat(r) {
+	case cooFormat:
+		w.Header().Set("Content-Type", cooContentType)
+		fmt.Fprint(w, s.String())
+		return
+	case mtxFormat:
+		writeSparseMtx(w, r, s)
+		return
+	}
+	if err := checkShapeLimits(s.Rows(), s.Cols(), getLimitsFromCtx(r.Context())); err != nil {
+		writeError(w, r, fmt.Errorf("%w, request the sparse result with ?format=coo or ?format=mtx", err))
+		return
+	}
+	writeMatrix(w, r, s.Dense())
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+
+	"matrix/pkg/matrix"
+)
+
+const (
+	// streamBufferSize is the size of the streamed output held back before it's sent to the client.
+	// The errors found before the first flush are reported with the proper status code
+	streamBufferSize = 64 * 1024
+)
+
+// csvRowReader reads the rows from CSV, the first row is read in advance to check that the matrix isn't empty.
+// The limits are checked as the rows are read, so the matrix over the limit is rejected without reading it to the end
+type csvRowReader struct {
+	first  []string
+	reader *csv.Reader
+	limits limits
+	count  int
+}
+
+func (c *csvRowReader) Read() ([]string, error) {
+	row := c.first
+	c.first = nil
+	if row == nil {
+		var err error
+		if row, err = c.reader.Read(); err != nil {
+			return nil, err
+		}
+	}
+	c.count++
+	if err := checkRowLimits(c.count, row, c.limits); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// openRows opens the row by row reader of the matrix from the JSON body, the raw CSV, Matrix Market, COO, NumPy or Excel body
+// or from the file of the multipart form. Only the CSV is read row by row, the other formats are read into memory at once,
+// the sparse ones are kept sparse, see sparseRows
+func openRows(w http.ResponseWriter, r *http.Request) (matrix.RowReader, error) {
+	switch {
+	case isJSONRequest(r):
+		m, err := readJSONMatrix(w, r)
+		if err != nil {
+			return nil, err
+		}
+		return m.RowReader(), nil
+	case isCsvRequest(r):
+		return openCsvRows(w, r, r.Body)
+	case isMtxRequest(r):
+		return openMtxRows(w, r, r.Body)
+	case isCooRequest(r):
+		return openCooRows(w, r, r.Body)
+	case isNpyRequest(r):
+		return openNpyRows(w, r, r.Body)
+	case isXlsxRequest(r):
+		return openXlsxRows(w, r, r.Body)
+	}
+	part, err := openMultipartPart(w, r, multipartFileKey)
+	if err != nil {
+		return nil, err
+	}
+	switch filepath.Ext(part.FileName()) {
+	case mtxExtension:
+		return openMtxRows(w, r, part)
+	case cooExtension:
+		return openCooRows(w, r, part)
+	case npyExtension, npzExtension:
+		return openNpyRows(w, r, part)
+	case xlsxExtension:
+		return openXlsxRows(w, r, part)
+	}
+	return openCsvRows(w, r, part)
+}
+
+// openMultipartPart finds the part of the multipart form without buffering the parts before it.
+// The extension is checked only when the part is sent as a file
+func openMultipartPart(w http.ResponseWriter, r *http.Request, key string) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			err = fmt.Errorf("%w: %q", errMissingFile, key)
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return nil, err
+		}
+		if part.FormName() != key {
+			continue
+		}
+
+		switch filepath.Ext(part.FileName()) {
+		case csvExtension, mtxExtension, cooExtension, npyExtension, npzExtension, xlsxExtension:
+		default:
+			if part.FileName() != "" {
+				writeError(w, r, errInvalidFileFormat)
+				return nil, errInvalidFileFormat
+			}
+		}
+		return part, nil
+	}
+}
+
+func openCsvRows(w http.ResponseWriter, r *http.Request, reader io.Reader) (matrix.RowReader, error) {
+	rows, err := newCsvRowReader(reader, getLimitsFromCtx(r.Context()))
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	return rows, nil
+}
+
+func openMtxRows(w http.ResponseWriter, r *http.Request, reader io.Reader) (matrix.RowReader, error) {
+	rows, err := readMatrixMarket(reader, getLimitsFromCtx(r.Context()))
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	return rows, nil
+}
+
+func openCooRows(w http.ResponseWriter, r *http.Request, reader io.Reader) (matrix.RowReader, error) {
+	l := getLimitsFromCtx(r.Context())
+	s, err := readTriplets(reader, l)
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	return newSparseRows(s, l), nil
+}
+
+func openNpyRows(w http.ResponseWriter, r *http.Request, reader io.Reader) (matrix.RowReader, error) {
+	m, err := readNumPy(reader, getLimitsFromCtx(r.Context()))
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	return m.RowReader(), nil
+}
+
+// openXlsxRows reads the sheet and the cell range selected with the "sheet" and "range" query parameters
+func openXlsxRows(w http.ResponseWriter, r *http.Request, reader io.Reader) (matrix.RowReader, error) {
+	selection := xlsxSelection{sheet: r.URL.Query().Get(sheetKey), cellRange: r.URL.Query().Get(rangeKey)}
+	m, err := readXlsx(reader, selection, getLimitsFromCtx(r.Context()))
+	if err != nil {
+		writeError(w, r, err)
+		return nil, err
+	}
+	return m.RowReader(), nil
+}
+
+// newCsvRowReader reads the first row in advance, so the empty matrix is reported before the rows are processed
+func newCsvRowReader(reader io.Reader, l limits) (*csvRowReader, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.ReuseRecord = true
+	first, err := csvReader.Read()
+	if errors.Is(err, io.EOF) {
+		err = matrix.ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the first row is copied, as it would be overwritten by the next Read
+	return &csvRowReader{first: append([]string(nil), first...), reader: csvReader, limits: l}, nil
+}
+
+// streamRows writes each row with writeRow as soon as it's read. When the error is found after the part of output
+// was already sent, the response is aborted, so the client gets the truncated body instead of the wrong one
+func streamRows(w http.ResponseWriter, r *http.Request, rows matrix.RowReader, writeRow func(out io.Writer, i int, row []string)) {
+	var buf bytes.Buffer
+	flushed := false
+	for i := 0; ; i++ {
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			w.Write(buf.Bytes())
+			return
+		}
+		if err != nil {
+			if !flushed {
+				writeError(w, r, err)
+				return
+			}
+			logError(err)
+			panic(http.ErrAbortHandler)
+		}
+
+		writeRow(&buf, i, row)
+		if buf.Len() >= streamBufferSize {
+			w.Write(buf.Bytes())
+			buf.Reset()
+			flushed = true
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_openCsvRows(t *testing.T) {
+	type args struct {
+		csv string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr string
+	}{
+		{
+			name: "open CSV rows happy path",
+			when: "the CSV reader reuses the rows",
+			then: "every row should be copied",
+
+			args: args{csv: "1,2\n3,4\n5,6"},
+			want: [][]string{{"1", "2"}, {"3", "4"}, {"5", "6"}},
+		},
+		{
+			name: "open CSV rows unhappy path",
+			when: "the rows have different length",
+			then: "error should be returned",
+
+			args:    args{csv: "1,2\n3"},
+			wantErr: "record on line 2: wrong number of fields",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := openCsvRows(httptest.NewRecorder(), newRequestWithLimits(defaultConfig().limits), strings.NewReader(tt.args.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := matrix.ReadAll(rows)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if records := got.Records(); !reflect.DeepEqual(records, tt.want) {
+				t.Errorf(errTemplate, meta, records, tt.want)
+			}
+		})
+	}
+}
+
+func Test_streamRows(t *testing.T) {
+	type args struct {
+		csv string
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args      args
+		wantBody  string
+		wantCode  int
+		wantAbort bool
+	}{
+		{
+			name: "stream rows happy path",
+			when: "everything is OK",
+			then: "all rows should be written",
+
+			args:     args{csv: "1,2\n3,4\n"},
+			wantBody: "1,2\n3,4\n",
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "stream rows unhappy path before flush",
+			when: "the error is found before the output is sent",
+			then: "error should be returned with bad request status",
+
+			args:     args{csv: "1,2\n3\n"},
+			wantBody: "record on line 2: wrong number of fields\n",
+			wantCode: http.StatusBadRequest,
+		},
+		{
+			name: "stream rows unhappy path after flush",
+			when: "the error is found after the part of output is sent",
+			then: "the response should be aborted",
+
+			args:      args{csv: strings.Repeat("1,2\n", streamBufferSize) + "3\n"},
+			wantAbort: true,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			req := newRequestWithLimits(limits{MaxRows: 2 * streamBufferSize, MaxCols: 2, MaxCellLength: 1})
+			rows, err := openCsvRows(recorder, req, strings.NewReader(tt.args.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer func() {
+				if got := recover(); (got == http.ErrAbortHandler) != tt.wantAbort {
+					t.Errorf(errTemplate, meta, got, tt.wantAbort)
+				}
+			}()
+			streamRows(recorder, req, rows, func(out io.Writer, _ int, row []string) {
+				fmt.Fprintf(out, "%s\n", strings.Join(row, ","))
+			})
+
+			if recorder.Code != tt.wantCode {
+				t.Errorf(errTemplate, meta, recorder.Code, tt.wantCode)
+			}
+			if got := recorder.Body.String(); got != tt.wantBody {
+				t.Errorf(errTemplate, meta, got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func Test_openCsvRows_streaming(t *testing.T) {
+	const rowsCount = 100000
+
+	// the matrix is generated on the fly, so it's never held in memory by the test either
+	body, writer := io.Pipe()
+	go func() {
+		for i := 0; i < rowsCount; i++ {
+			fmt.Fprintf(writer, "%d,%d\n", i, -i+1)
+		}
+		writer.Close()
+	}()
+
+	rows, err := openCsvRows(httptest.NewRecorder(), newRequestWithLimits(limits{MaxRows: rowsCount, MaxCols: 2, MaxCellLength: 6}), body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := matrix.SumRows(matrix.Int, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprint(rowsCount); got != want {
+		t.Errorf(errTemplate, "sum of the streamed rows", got, want)
+	}
+}
+
+// newRequestWithLimits creates the request with the limits set like by limitsMiddleware
+func newRequestWithLimits(l limits) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	return req.WithContext(context.WithValue(req.Context(), limitsKey, l))
+}
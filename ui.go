@@ -0,0 +1,26 @@
+package main
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+)
+
+//go:embed ui.html
+var uiHTML string
+
+// uiTemplate is the browser UI listing the registered operations, it sends the same requests as curl to their routes
+var uiTemplate = template.Must(template.New("ui").Parse(uiHTML))
+
+// UI serves the page for the people who don't use curl, the other paths are not found
+func (Handler) UI(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := uiTemplate.Execute(w, operations); err != nil {
+		// the status is already sent, so it's only possible to log the error
+		logError(err)
+	}
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"matrix/pkg/matrix"
+)
+
+var (
+	errInvalidXlsx     = errors.New("invalid Excel workbook")
+	errUnknownSheet    = errors.New("no such sheet in the workbook")
+	errInvalidRange    = errors.New("invalid cell range, e.g. A1:C3 or B2 allowed")
+	errMergedCells     = errors.New("merged cells aren't allowed in matrix")
+	errEmptyCells      = errors.New("empty cells aren't allowed in matrix")
+	errUncachedFormula = errors.New("formula cells should have the cached value, open and save the workbook in Excel to calculate them")
+)
+
+const (
+	xlsxExtension   = ".xlsx"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+	// the parts of the workbook, the paths of the sheets are found in the workbook relationships
+	xlsxWorkbookPath      = "xl/workbook.xml"
+	xlsxRelationshipsPath = "xl/_rels/workbook.xml.rels"
+	xlsxSharedStringsPath = "xl/sharedStrings.xml"
+
+	// the cell types, the numbers have no type
+	xlsxSharedString = "s"
+	xlsxInlineString = "inlineStr"
+
+	// the size of the Excel sheet
+	xlsxMaxRows = 1048576
+	xlsxMaxCols = 16384
+)
+
+// xlsxSelection is the part of the workbook read as matrix, the first sheet and all its filled cells by default
+type xlsxSelection struct {
+	sheet     string
+	cellRange string // e.g. A1:C3
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"id,attr"` // r:id, the relationship to the sheet part
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxText is the plain text or the text of several formatted runs
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	var b strings.Builder
+	b.WriteString(t.Text)
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+	return b.String()
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Ref   int        `xml:"r,attr"` // 1-based, optional
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+	MergeCells []struct {
+		Ref string `xml:"ref,attr"`
+	} `xml:"mergeCells>mergeCell"`
+}
+
+type xlsxCell struct {
+	Ref     string    `xml:"r,attr"` // e.g. B3, optional
+	Type    string    `xml:"t,attr"`
+	Formula *struct{} `xml:"f"`
+	Value   *string   `xml:"v"` // the cached value of the formula cell
+	Inline  *xlsxText `xml:"is"`
+}
+
+// cellRange is the rectangle of the sheet cells, the rows and columns are 1-based and inclusive
+type cellRange struct {
+	top, left, bottom, right int
+}
+
+func (c cellRange) overlaps(other cellRange) bool {
+	return c.top <= other.bottom && other.top <= c.bottom && c.left <= other.right && other.left <= c.right
+}
+
+func (c cellRange) contains(row, col int) bool {
+	return c.top <= row && row <= c.bottom && c.left <= col && col <= c.right
+}
+
+// extend grows the range to hold the cell
+func (c *cellRange) extend(row, col int) {
+	if row < c.top {
+		c.top = row
+	}
+	if row > c.bottom {
+		c.bottom = row
+	}
+	if col < c.left {
+		c.left = col
+	}
+	if col > c.right {
+		c.right = col
+	}
+}
+
+// readXlsx reads the selected cells of the workbook. The formula cells have their values cached by Excel,
+// the merged and empty cells of the selection are reported as errors, as they have no matrix element
+func readXlsx(reader io.Reader, selection xlsxSelection, l limits) (*matrix.Text, error) {
+	// zip keeps its directory at the end, so the workbook is read into memory
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, matrix.ErrEmpty
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidXlsx, err.Error())
+	}
+
+	sheetPath, err := findSheet(archive, selection.sheet, l)
+	if err != nil {
+		return nil, err
+	}
+	// the workbook without text cells has no shared strings
+	var sharedStrings xlsxSharedStrings
+	if _, err := fs.Stat(archive, xlsxSharedStringsPath); err == nil {
+		if err := decodeXlsxPart(archive, xlsxSharedStringsPath, &sharedStrings, l); err != nil {
+			return nil, err
+		}
+	}
+	var sheet xlsxWorksheet
+	if err := decodeXlsxPart(archive, sheetPath, &sheet, l); err != nil {
+		return nil, err
+	}
+
+	cells, used, err := sheetCells(sheet, sharedStrings)
+	if err != nil {
+		return nil, err
+	}
+	selected := used
+	if selection.cellRange != "" {
+		if selected, err = parseCellRange(selection.cellRange); err != nil {
+			return nil, err
+		}
+	} else if len(cells) == 0 {
+		return nil, matrix.ErrEmpty
+	}
+	rows, cols := selected.bottom-selected.top+1, selected.right-selected.left+1
+	if err := checkShapeLimits(rows, cols, l); err != nil {
+		return nil, err
+	}
+	for _, merged := range sheet.MergeCells {
+		mergedRange, err := parseCellRange(merged.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidXlsx, err.Error())
+		}
+		if selected.overlaps(mergedRange) {
+			return nil, fmt.Errorf("%w: %s", errMergedCells, merged.Ref)
+		}
+	}
+
+	// the range with more cells than the sheet fills has the empty ones, so it's rejected before the records are allocated
+	if rows*cols > len(cells) {
+		return nil, emptyCellsError(cells, selected)
+	}
+
+	records := make([][]string, rows)
+	var empty, uncached cellNames
+	for i := range records {
+		records[i] = make([]string, cols)
+		for j := range records[i] {
+			row, col := selected.top+i, selected.left+j
+			cell, ok := cells[[2]int{row, col}]
+			switch {
+			case ok && cell.uncached:
+				uncached.add(row, col)
+			case !ok || cell.value == "":
+				empty.add(row, col)
+			default:
+				records[i][j] = cell.value
+			}
+		}
+	}
+	if uncached.count > 0 {
+		return nil, uncached.error(errUncachedFormula)
+	}
+	if empty.count > 0 {
+		return nil, empty.error(errEmptyCells)
+	}
+	m, err := matrix.NewText(records)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMatrixLimits(m, l); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// findSheet finds the path of the sheet part by the sheet name, the first sheet is used when the name is empty
+func findSheet(archive *zip.Reader, name string, l limits) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodeXlsxPart(archive, xlsxWorkbookPath, &workbook, l); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: the workbook has no sheets", errInvalidXlsx)
+	}
+	id := workbook.Sheets[0].ID
+	if name != "" {
+		id = ""
+		names := make([]string, len(workbook.Sheets))
+		for k, sheet := range workbook.Sheets {
+			names[k] = strconv.Quote(sheet.Name)
+			if sheet.Name == name {
+				id = sheet.ID
+			}
+		}
+		if id == "" {
+			return "", fmt.Errorf("%w: %q, the sheets are %s", errUnknownSheet, name, strings.Join(names, ", "))
+		}
+	}
+
+	var relationships xlsxRelationships
+	if err := decodeXlsxPart(archive, xlsxRelationshipsPath, &relationships, l); err != nil {
+		return "", err
+	}
+	for _, rel := range relationships.Relationships {
+		if rel.ID != id {
+			continue
+		}
+		// the target is relative to the workbook part, unless it's absolute
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join(path.Dir(xlsxWorkbookPath), rel.Target), nil
+	}
+	return "", fmt.Errorf("%w: the sheet part %q isn't found", errInvalidXlsx, id)
+}
+
+// decodeXlsxPart decodes the XML part of the workbook. The part is decompressed up to the max upload size,
+// so the small archive can't expand into the huge one
+func decodeXlsxPart(archive *zip.Reader, name string, v any, l limits) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidXlsx, err.Error())
+	}
+	defer file.Close()
+	limited := &io.LimitedReader{R: file, N: l.MaxUploadSize}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		// the part cut at the limit is invalid XML
+		if limited.N == 0 {
+			return fmt.Errorf("%w: %s takes more than %d bytes, the max upload size limit", errBodyTooLarge, name, l.MaxUploadSize)
+		}
+		return fmt.Errorf("%w: %s: %s", errInvalidXlsx, name, err.Error())
+	}
+	return nil
+}
+
+// xlsxValue is the value of the sheet cell, the formula cell saved without calculation has no value
+type xlsxValue struct {
+	value    string
+	uncached bool
+}
+
+// sheetCells collects the values of the cells by their 1-based row and column, and the range of the filled cells
+func sheetCells(sheet xlsxWorksheet, sharedStrings xlsxSharedStrings) (map[[2]int]xlsxValue, cellRange, error) {
+	cells := make(map[[2]int]xlsxValue)
+	var used cellRange
+	row := 0
+	for _, r := range sheet.Rows {
+		// the row and cell references are optional, the omitted ones follow the previous ones
+		row++
+		if r.Ref != 0 {
+			row = r.Ref
+		}
+		col := 0
+		for _, c := range r.Cells {
+			col++
+			if c.Ref != "" {
+				var err error
+				if row, col, err = parseCellName(c.Ref); err != nil {
+					return nil, cellRange{}, fmt.Errorf("%w: %s", errInvalidXlsx, err.Error())
+				}
+			}
+
+			var v xlsxValue
+			switch {
+			case c.Type == xlsxInlineString && c.Inline != nil:
+				v.value = c.Inline.String()
+			case c.Value == nil:
+				// the styled cell without value is empty, unless it's the formula
+				if c.Formula == nil {
+					continue
+				}
+				v.uncached = true
+			case c.Type == xlsxSharedString:
+				k, err := strconv.Atoi(*c.Value)
+				if err != nil || k < 0 || k >= len(sharedStrings.Items) {
+					return nil, cellRange{}, fmt.Errorf("%w: %s refers to the missing shared string %q", errInvalidXlsx, cellName(row, col), *c.Value)
+				}
+				v.value = sharedStrings.Items[k].String()
+			default:
+				v.value = *c.Value
+			}
+			v.value = strings.TrimSpace(v.value)
+			cells[[2]int{row, col}] = v
+
+			if len(cells) == 1 {
+				used = cellRange{top: row, left: col, bottom: row, right: col}
+			}
+			used.extend(row, col)
+		}
+	}
+	return cells, used, nil
+}
+
+// parseCellRange parses the range of the cells, e.g. A1:C3, or the single cell, e.g. B2
+func parseCellRange(s string) (cellRange, error) {
+	first, last, found := strings.Cut(strings.ToUpper(strings.TrimSpace(s)), ":")
+	if !found {
+		last = first
+	}
+	top, left, err := parseCellName(first)
+	if err != nil {
+		return cellRange{}, err
+	}
+	bottom, right, err := parseCellName(last)
+	if err != nil {
+		return cellRange{}, err
+	}
+	if bottom < top || right < left {
+		return cellRange{}, fmt.Errorf("%w: %q should start with the top left cell", errInvalidRange, s)
+	}
+	return cellRange{top: top, left: left, bottom: bottom, right: right}, nil
+}
+
+// parseCellName parses the cell name, e.g. AB12, into the 1-based row and column. The $ of the absolute reference is ignored
+func parseCellName(name string) (row, col int, err error) {
+	name = strings.ReplaceAll(name, "$", "")
+	k := 0
+	for ; k < len(name) && name[k] >= 'A' && name[k] <= 'Z'; k++ {
+		col = col*26 + int(name[k]-'A') + 1
+		if col > xlsxMaxCols {
+			return 0, 0, fmt.Errorf("%w: %q", errInvalidRange, name)
+		}
+	}
+	row, err = strconv.Atoi(name[k:])
+	if k == 0 || err != nil || row < 1 || row > xlsxMaxRows {
+		return 0, 0, fmt.Errorf("%w: %q", errInvalidRange, name)
+	}
+	return row, col, nil
+}
+
+// cellName is the name of the cell with 1-based row and column, e.g. AB12
+func cellName(row, col int) string {
+	var letters []byte
+	for ; col > 0; col = (col - 1) / 26 {
+		letters = append([]byte{byte('A' + (col-1)%26)}, letters...)
+	}
+	return string(letters) + strconv.Itoa(row)
+}
+
+// cellNames are the cells listed in the error, only the first MaxCellErrors names are kept and the rest are counted
+type cellNames struct {
+	names []string
+	count int
+}
+
+func (c *cellNames) add(row, col int) {
+	if len(c.names) < matrix.MaxCellErrors {
+		c.names = append(c.names, cellName(row, col))
+	}
+	c.count++
+}
+
+func (c cellNames) error(err error) error {
+	if c.count > len(c.names) {
+		return fmt.Errorf("%w: %s and %d more", err, strings.Join(c.names, ", "), c.count-len(c.names))
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(c.names, ", "))
+}
+
+// emptyCellsError reports the empty cells of the range larger than the filled cells. The empty cells are counted
+// from the filled ones, and only the range up to the first MaxCellErrors empty cells is visited
+func emptyCellsError(cells map[[2]int]xlsxValue, selected cellRange) error {
+	var empty cellNames
+	filled := func(row, col int) bool {
+		cell, ok := cells[[2]int{row, col}]
+		return ok && (cell.uncached || cell.value != "")
+	}
+	for row := selected.top; row <= selected.bottom && len(empty.names) < matrix.MaxCellErrors; row++ {
+		for col := selected.left; col <= selected.right && len(empty.names) < matrix.MaxCellErrors; col++ {
+			if !filled(row, col) {
+				empty.add(row, col)
+			}
+		}
+	}
+	empty.count = (selected.bottom - selected.top + 1) * (selected.right - selected.left + 1)
+	for pos := range cells {
+		if selected.contains(pos[0], pos[1]) && filled(pos[0], pos[1]) {
+			empty.count--
+		}
+	}
+	return empty.error(errEmptyCells)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"matrix/pkg/matrix"
+)
+
+func Test_readXlsx(t *testing.T) {
+	type args struct {
+		selection xlsxSelection
+		limits    limits
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args    args
+		want    [][]string
+		wantErr error
+	}{
+		{
+			name: "read Excel happy path with default selection",
+			when: "neither sheet nor range is selected",
+			then: "all filled cells of the first sheet should be returned, the formula should have the cached value",
+
+			want: [][]string{{"1", "2", "3"}, {"4", "5", "6"}},
+		},
+		{
+			name: "read Excel happy path with sheet and range",
+			when: "the range of the second sheet is selected in lower case",
+			then: "the cells of the range should be returned",
+
+			args: args{selection: xlsxSelection{sheet: "Report", cellRange: "a1:b3"}},
+			want: [][]string{{"Quarter", "Revenue"}, {"Q1", "10"}, {"Q2", "20.5"}},
+		},
+		{
+			name: "read Excel happy path with single cell",
+			when: "the range is one cell with absolute reference",
+			then: "1x1 matrix should be returned",
+
+			args: args{selection: xlsxSelection{sheet: "Report", cellRange: "$B$2"}},
+			want: [][]string{{"10"}},
+		},
+		{
+			name: "read Excel unhappy path with unknown sheet",
+			when: "the sheet doesn't exist",
+			then: "error should be returned",
+
+			args:    args{selection: xlsxSelection{sheet: "Summary"}},
+			wantErr: errUnknownSheet,
+		},
+		{
+			name: "read Excel unhappy path with invalid range",
+			when: "the range isn't the cell names",
+			then: "error should be returned",
+
+			args:    args{selection: xlsxSelection{cellRange: "1A:C3"}},
+			wantErr: errInvalidRange,
+		},
+		{
+			name: "read Excel unhappy path with merged cells",
+			when: "the range overlaps the merged cells",
+			then: "error should be returned",
+
+			args:    args{selection: xlsxSelection{sheet: "Report", cellRange: "B4:B5"}},
+			wantErr: errMergedCells,
+		},
+		{
+			name: "read Excel unhappy path with empty cells",
+			when: "the range has the cell without value",
+			then: "error should be returned",
+
+			args:    args{selection: xlsxSelection{sheet: "Report", cellRange: "A3:B4"}},
+			wantErr: errEmptyCells,
+		},
+		{
+			name: "read Excel unhappy path with uncached formula",
+			when: "the formula cell has no cached value",
+			then: "error should be returned",
+
+			args:    args{selection: xlsxSelection{sheet: "Report", cellRange: "B2:C2"}},
+			wantErr: errUncachedFormula,
+		},
+		{
+			name: "read Excel unhappy path with too large range",
+			when: "the range exceeds the limits",
+			then: "error should be returned",
+
+			args:    args{selection: xlsxSelection{cellRange: "A1:A20000"}, limits: defaultConfig().limits},
+			wantErr: errMatrixTooLarge,
+		},
+		{
+			name: "read Excel unhappy path with range larger than the sheet",
+			when: "the range within the limits has more cells than the sheet fills",
+			then: "error should be returned before the cells are allocated",
+
+			args:    args{selection: xlsxSelection{cellRange: "A1:ALL10000"}},
+			wantErr: errEmptyCells,
+		},
+		{
+			name: "read Excel unhappy path with too large part",
+			when: "the decompressed part of the workbook exceeds the max upload size",
+			then: "error should be returned",
+
+			args:    args{limits: limits{MaxUploadSize: 100, MaxRows: 10, MaxCols: 10, MaxCellLength: 10}},
+			wantErr: errBodyTooLarge,
+		},
+	}
+	data := readTestFile(t, xlsxPath)
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.args.limits
+			if l == (limits{}) {
+				l = unlimited
+			}
+			got, err := readXlsx(bytes.NewReader(data), tt.args.selection, l)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf(errTemplate, meta, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Records(), tt.want) {
+				t.Errorf(errTemplate, meta, got.Records(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_readXlsx_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		data    []byte
+		wantErr error
+	}{
+		{
+			name: "read Excel unhappy path with empty file",
+			when: "the file is empty",
+			then: "error should be returned",
+
+			data:    nil,
+			wantErr: matrix.ErrEmpty,
+		},
+		{
+			name: "read Excel unhappy path with CSV",
+			when: "the file isn't zip archive",
+			then: "error should be returned",
+
+			data:    []byte("1,2\n3,4\n"),
+			wantErr: errInvalidXlsx,
+		},
+		{
+			name: "read Excel unhappy path with other archive",
+			when: "the zip archive has no workbook",
+			then: "error should be returned",
+
+			data:    readTestFile(t, npzPath),
+			wantErr: errInvalidXlsx,
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readXlsx(bytes.NewReader(tt.data), xlsxSelection{}, unlimited)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf(errTemplate, meta, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_cellName(t *testing.T) {
+	type args struct {
+		row int
+		col int
+	}
+	tests := []struct {
+		name string
+		when string // description of testData conditions
+		then string // description of expected result
+
+		args args
+		want string
+	}{
+		{
+			name: "cell name happy path with one letter",
+			when: "the column is the last one named with one letter",
+			then: "the name should be parsed back to the same cell",
+
+			args: args{row: 2, col: 26},
+			want: "Z2",
+		},
+		{
+			name: "cell name happy path with two letters",
+			when: "the column is the first one named with two letters",
+			then: "the name should be parsed back to the same cell",
+
+			args: args{row: 3, col: 27},
+			want: "AA3",
+		},
+		{
+			name: "cell name happy path with the last cell",
+			when: "the cell is the last one of the sheet",
+			then: "the name should be parsed back to the same cell",
+
+			args: args{row: xlsxMaxRows, col: xlsxMaxCols},
+			want: "XFD1048576",
+		},
+	}
+	for i, tt := range tests {
+		meta := fmt.Sprintf(metaTemplate, tt.name, i, tt.when, tt.then)
+
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cellName(tt.args.row, tt.args.col); got != tt.want {
+				t.Errorf(errTemplate, meta, got, tt.want)
+			}
+			row, col, err := parseCellName(tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := (args{row: row, col: col}); got != tt.args {
+				t.Errorf(errTemplate, meta, got, tt.args)
+			}
+		})
+	}
+}